@@ -0,0 +1,57 @@
+package device
+
+import (
+	"time"
+)
+
+const (
+	// DisconnectReasonServer is the AuditRecord.Reason and Event.Reason used when the server, e.g. via Disconnect
+	// or DisconnectIf, requested the disconnection
+	DisconnectReasonServer = "server disconnect"
+
+	// DisconnectReasonDevice is the AuditRecord.Reason and Event.Reason used when the device closed its connection
+	// cleanly and no other error occurred
+	DisconnectReasonDevice = "device disconnect"
+
+	// DisconnectReasonIdle is the AuditRecord.Reason and Event.Reason used when the device sent no messages
+	// and answered no pings within the IdlePeriod
+	DisconnectReasonIdle = "idle timeout"
+)
+
+// AuditRecord is a single, structured record of a device connecting or disconnecting.
+type AuditRecord struct {
+	// ID is the device's identifier
+	ID ID
+
+	// RemoteAddr is the network address of the device, as reported by the HTTP request
+	// that initiated the connection
+	RemoteAddr string
+
+	// Timestamp is the time at which the connection or disconnection occurred
+	Timestamp time.Time
+
+	// Reason describes why a disconnection occurred.  This will be DisconnectReasonServer,
+	// DisconnectReasonDevice, DisconnectReasonIdle, or the text of the error that terminated the connection.
+	// Reason is always empty for connections.
+	Reason string
+}
+
+// AuditSink receives a record of every device connection and disconnection.  Unlike a Listener,
+// an AuditSink is intended to write records to some durable store, and its methods are
+// never passed reused or mutable objects.
+//
+// Implementations must be safe for concurrent use, as they will be invoked from many
+// device goroutines at once.
+type AuditSink interface {
+	// Connected records that a device has connected
+	Connected(AuditRecord)
+
+	// Disconnected records that a device has disconnected
+	Disconnected(AuditRecord)
+}
+
+// nopAuditSink is the AuditSink used when none is configured
+type nopAuditSink struct{}
+
+func (nopAuditSink) Connected(AuditRecord)    {}
+func (nopAuditSink) Disconnected(AuditRecord) {}
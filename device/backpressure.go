@@ -0,0 +1,52 @@
+package device
+
+import (
+	"time"
+
+	"github.com/Comcast/webpa-common/wrp"
+)
+
+const (
+	// BackpressureCloseCode is the websocket close code sent to devices told to reconnect
+	// later via BackpressureDirective.Close.  This is the standard "Try Again Later" code.
+	BackpressureCloseCode = 1013
+
+	// BackpressureDurationKey is the WRP metadata key holding the backoff duration in
+	// backpressure messages.  The value is formatted as with time.Duration.String.
+	BackpressureDurationKey = "backpressure-duration"
+)
+
+// BackpressureDirective describes how devices should back off when the server is overloaded.
+type BackpressureDirective struct {
+	// Duration is how long devices should back off
+	Duration time.Duration
+
+	// Close indicates that devices should be disconnected and reconnect, possibly elsewhere,
+	// after Duration.  Devices are closed with BackpressureCloseCode, and the close reason
+	// is Duration formatted as with time.Duration.String.
+	//
+	// If Close is false, devices remain connected and are sent a WRP simple event carrying
+	// Duration in its metadata under BackpressureDurationKey.
+	Close bool
+
+	// Source is the WRP source of backpressure messages, usually identifying this server.
+	// It is unused when Close is set.
+	Source string
+}
+
+// closeReason is the text sent in the close frame for this directive
+func (bd BackpressureDirective) closeReason() string {
+	return bd.Duration.String()
+}
+
+// newMessage creates the WRP message that tells a device to back off
+func (bd BackpressureDirective) newMessage(id ID) *wrp.Message {
+	return &wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Source:      bd.Source,
+		Destination: string(id),
+		Metadata: map[string]string{
+			BackpressureDurationKey: bd.Duration.String(),
+		},
+	}
+}
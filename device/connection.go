@@ -55,9 +55,19 @@ type Connection interface {
 	// This method cannot be called concurrently with Write().
 	SetPongCallback(func(string))
 
-	// SendClose transmits a close frame to the device.  After this method is invoked,
+	// WriteBefore writes a single frame, just like Write, except that the write must complete
+	// by the earlier of the given deadline and this connection's configured write timeout.
+	// A zero deadline means that only the write timeout applies.  This method must not be
+	// invoked concurrently with Write.
+	WriteBefore([]byte, time.Time) (int, error)
+
+	// SendClose transmits a normal close frame to the device.  After this method is invoked,
 	// the only method that should be invoked is Close()
 	SendClose() error
+
+	// SendCloseCode transmits a close frame with the given close code and reason text.  As with
+	// SendClose, the only method that should be invoked afterward is Close()
+	SendCloseCode(int, string) error
 }
 
 // connection is the internal implementation of Connection
@@ -140,6 +150,19 @@ func (c *connection) Read(target io.ReaderFrom) (frameRead bool, err error) {
 }
 
 func (c *connection) Write(message []byte) (int, error) {
+	return c.WriteBefore(message, time.Time{})
+}
+
+func (c *connection) WriteBefore(message []byte, deadline time.Time) (int, error) {
+	writeDeadline := c.nextWriteDeadline()
+	if !deadline.IsZero() && (writeDeadline.IsZero() || deadline.Before(writeDeadline)) {
+		writeDeadline = deadline
+	}
+
+	if err := c.webSocket.SetWriteDeadline(writeDeadline); err != nil {
+		return 0, err
+	}
+
 	if err := c.webSocket.WriteMessage(websocket.BinaryMessage, message); err != nil {
 		return 0, err
 	}
@@ -152,9 +175,13 @@ func (c *connection) Close() error {
 }
 
 func (c *connection) SendClose() error {
+	return c.SendCloseCode(websocket.CloseNormalClosure, "close")
+}
+
+func (c *connection) SendCloseCode(code int, text string) error {
 	return c.webSocket.WriteControl(
 		websocket.CloseMessage,
-		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "close"),
+		websocket.FormatCloseMessage(code, text),
 		c.nextWriteDeadline(),
 	)
 }
@@ -174,21 +201,24 @@ type ConnectionFactory interface {
 func NewConnectionFactory(o *Options) ConnectionFactory {
 	return &connectionFactory{
 		upgrader: websocket.Upgrader{
-			HandshakeTimeout: o.handshakeTimeout(),
-			ReadBufferSize:   o.readBufferSize(),
-			WriteBufferSize:  o.writeBufferSize(),
-			Subprotocols:     o.subprotocols(),
+			HandshakeTimeout:  o.handshakeTimeout(),
+			ReadBufferSize:    o.readBufferSize(),
+			WriteBufferSize:   o.writeBufferSize(),
+			Subprotocols:      o.subprotocols(),
+			EnableCompression: o.enableCompression(),
 		},
-		idlePeriod:   o.idlePeriod(),
-		writeTimeout: o.writeTimeout(),
+		compressionLevel: o.compressionLevel(),
+		idlePeriod:       o.idlePeriod(),
+		writeTimeout:     o.writeTimeout(),
 	}
 }
 
 // connectionFactory is the default ConnectionFactory implementation
 type connectionFactory struct {
-	upgrader     websocket.Upgrader
-	idlePeriod   time.Duration
-	writeTimeout time.Duration
+	upgrader         websocket.Upgrader
+	compressionLevel int
+	idlePeriod       time.Duration
+	writeTimeout     time.Duration
 }
 
 func (cf *connectionFactory) NewConnection(response http.ResponseWriter, request *http.Request, responseHeader http.Header) (Connection, error) {
@@ -197,6 +227,13 @@ func (cf *connectionFactory) NewConnection(response http.ResponseWriter, request
 		return nil, err
 	}
 
+	if cf.upgrader.EnableCompression {
+		if err := webSocket.SetCompressionLevel(cf.compressionLevel); err != nil {
+			webSocket.Close()
+			return nil, err
+		}
+	}
+
 	c := &connection{
 		webSocket:    webSocket,
 		idlePeriod:   cf.idlePeriod,
@@ -220,8 +257,9 @@ type Dialer interface {
 // If an Options is supplied, the appropriate settings will override any gorilla Dialer, e.g. ReadBufferSize.
 func NewDialer(o *Options, d *websocket.Dialer) Dialer {
 	dialer := &dialer{
-		idlePeriod:   o.idlePeriod(),
-		writeTimeout: o.writeTimeout(),
+		compressionLevel: o.compressionLevel(),
+		idlePeriod:       o.idlePeriod(),
+		writeTimeout:     o.writeTimeout(),
 	}
 
 	if d != nil {
@@ -235,6 +273,7 @@ func NewDialer(o *Options, d *websocket.Dialer) Dialer {
 		dialer.webSocketDialer.ReadBufferSize = o.readBufferSize()
 		dialer.webSocketDialer.WriteBufferSize = o.writeBufferSize()
 		dialer.webSocketDialer.Subprotocols = o.subprotocols()
+		dialer.webSocketDialer.EnableCompression = o.enableCompression()
 	}
 
 	return dialer
@@ -242,9 +281,10 @@ func NewDialer(o *Options, d *websocket.Dialer) Dialer {
 
 // dialer is the internal implementation of Dialer.  This implemention wraps a gorilla Dialer
 type dialer struct {
-	webSocketDialer websocket.Dialer
-	idlePeriod      time.Duration
-	writeTimeout    time.Duration
+	webSocketDialer  websocket.Dialer
+	compressionLevel int
+	idlePeriod       time.Duration
+	writeTimeout     time.Duration
 }
 
 func (d *dialer) Dial(URL string, id ID, extra http.Header) (Connection, *http.Response, error) {
@@ -259,6 +299,13 @@ func (d *dialer) Dial(URL string, id ID, extra http.Header) (Connection, *http.R
 		return nil, response, err
 	}
 
+	if d.webSocketDialer.EnableCompression {
+		if err := webSocket.SetCompressionLevel(d.compressionLevel); err != nil {
+			webSocket.Close()
+			return nil, response, err
+		}
+	}
+
 	c := &connection{
 		webSocket:    webSocket,
 		idlePeriod:   d.idlePeriod,
@@ -0,0 +1,196 @@
+package device
+
+import (
+	"bytes"
+	"compress/flate"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startStalledServer starts a websocket server that never reads from its connections,
+// so that sufficiently large writes from a client will block.  The returned channel must
+// be closed to release the server's connections.
+func startStalledServer(t *testing.T) (*httptest.Server, string, chan struct{}) {
+	var (
+		release  = make(chan struct{})
+		upgrader websocket.Upgrader
+		server   = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			c, err := upgrader.Upgrade(response, request, nil)
+			if err != nil {
+				t.Logf("Unable to upgrade: %s", err)
+				return
+			}
+
+			<-release
+			c.Close()
+		}))
+	)
+
+	return server, "ws" + strings.TrimPrefix(server.URL, "http"), release
+}
+
+func testConnectionWriteBeforeDeadline(t *testing.T, o *Options, deadline func() time.Time, expectedTimeout time.Duration) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		server, connectURL, release = startStalledServer(t)
+		dialer                      = NewDialer(o, nil)
+	)
+
+	defer server.Close()
+	defer close(release)
+
+	connection, _, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	require.NoError(err)
+	defer connection.Close()
+
+	// the message must be large enough to fill the network buffers, so that the write blocks
+	var (
+		message = make([]byte, 64*1024*1024)
+		start   = time.Now()
+	)
+
+	_, err = connection.WriteBefore(message, deadline())
+	elapsed := time.Since(start)
+
+	require.Error(err)
+	netError, ok := err.(net.Error)
+	require.True(ok, "expected a net.Error, got %T", err)
+	assert.True(netError.Timeout())
+	assert.True(elapsed >= expectedTimeout, "the write ended after %s, before the %s deadline", elapsed, expectedTimeout)
+	assert.True(elapsed < expectedTimeout+2*time.Second, "the write ended after %s, well after the %s deadline", elapsed, expectedTimeout)
+}
+
+func TestConnectionWriteBefore(t *testing.T) {
+	t.Run("ContextDeadline", func(t *testing.T) {
+		testConnectionWriteBeforeDeadline(
+			t,
+			&Options{WriteTimeout: time.Hour},
+			func() time.Time { return time.Now().Add(200 * time.Millisecond) },
+			200*time.Millisecond,
+		)
+	})
+
+	t.Run("WriteTimeout", func(t *testing.T) {
+		testConnectionWriteBeforeDeadline(
+			t,
+			&Options{WriteTimeout: 200 * time.Millisecond},
+			func() time.Time { return time.Now().Add(time.Hour) },
+			200*time.Millisecond,
+		)
+	})
+
+	t.Run("NoDeadline", func(t *testing.T) {
+		testConnectionWriteBeforeDeadline(
+			t,
+			&Options{WriteTimeout: 200 * time.Millisecond},
+			func() time.Time { return time.Time{} },
+			200*time.Millisecond,
+		)
+	})
+}
+
+// startEchoServer starts a websocket server, using a ConnectionFactory created from the given Options,
+// that writes each message it reads back to the client
+func startEchoServer(t *testing.T, o *Options) (*httptest.Server, string) {
+	var (
+		factory = NewConnectionFactory(o)
+		server  = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			c, err := factory.NewConnection(response, request, nil)
+			if err != nil {
+				t.Logf("Unable to create connection: %s", err)
+				return
+			}
+
+			defer c.Close()
+			for {
+				frame, err := c.NextReader()
+				if err != nil {
+					return
+				}
+
+				message, err := ioutil.ReadAll(frame)
+				if err != nil {
+					return
+				}
+
+				if _, err := c.Write(message); err != nil {
+					return
+				}
+			}
+		}))
+	)
+
+	return server, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func testConnectionCompression(t *testing.T, serverOptions, clientOptions *Options, expectCompression bool) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		server, connectURL = startEchoServer(t, serverOptions)
+		dialer             = NewDialer(clientOptions, nil)
+
+		// repetitive JSON, like the payloads devices actually send, so that compression has an effect
+		message = bytes.Repeat([]byte(`{"name": "device-status", "value": "online"}`), 16*1024)
+	)
+
+	defer server.Close()
+
+	connection, response, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	require.NoError(err)
+	require.NotNil(response)
+	defer connection.Close()
+
+	assert.Equal(
+		expectCompression,
+		strings.Contains(response.Header.Get("Sec-Websocket-Extensions"), "permessage-deflate"),
+	)
+
+	_, err = connection.Write(message)
+	require.NoError(err)
+
+	frame, err := connection.NextReader()
+	require.NoError(err)
+	echoed, err := ioutil.ReadAll(frame)
+	require.NoError(err)
+	assert.Equal(message, echoed)
+}
+
+func TestConnectionCompression(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		testConnectionCompression(t, nil, nil, false)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		testConnectionCompression(t, &Options{EnableCompression: true}, &Options{EnableCompression: true}, true)
+	})
+
+	t.Run("CompressionLevel", func(t *testing.T) {
+		testConnectionCompression(
+			t,
+			&Options{EnableCompression: true, CompressionLevel: flate.BestCompression},
+			&Options{EnableCompression: true, CompressionLevel: flate.HuffmanOnly},
+			true,
+		)
+	})
+
+	t.Run("ServerOnly", func(t *testing.T) {
+		testConnectionCompression(t, &Options{EnableCompression: true}, nil, false)
+	})
+
+	t.Run("DeviceOnly", func(t *testing.T) {
+		testConnectionCompression(t, nil, &Options{EnableCompression: true}, false)
+	})
+}
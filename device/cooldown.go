@@ -0,0 +1,60 @@
+package device
+
+import (
+	"sync"
+	"time"
+)
+
+// cooldowns tracks the device IDs which are not allowed to reconnect until
+// some point in the future.  Entries are removed automatically once their
+// cooldown period has elapsed.
+type cooldowns struct {
+	lock     sync.Mutex
+	period   time.Duration
+	now      func() time.Time
+	deadline map[ID]time.Time
+}
+
+func newCooldowns(period time.Duration) *cooldowns {
+	return &cooldowns{
+		period:   period,
+		now:      time.Now,
+		deadline: make(map[ID]time.Time),
+	}
+}
+
+// start begins a cooldown for the given device ID.  Any existing cooldown
+// for that ID is replaced.
+func (c *cooldowns) start(id ID) {
+	if c.period < 1 {
+		return
+	}
+
+	c.lock.Lock()
+	deadline := c.now().Add(c.period)
+	c.deadline[id] = deadline
+	c.lock.Unlock()
+
+	time.AfterFunc(c.period, func() {
+		c.lock.Lock()
+		if c.deadline[id] == deadline {
+			delete(c.deadline, id)
+		}
+
+		c.lock.Unlock()
+	})
+}
+
+// remaining returns the time left in the given device ID's cooldown.  If the
+// device is allowed to connect, this method returns a nonpositive duration.
+func (c *cooldowns) remaining(id ID) time.Duration {
+	c.lock.Lock()
+	deadline, ok := c.deadline[id]
+	c.lock.Unlock()
+
+	if !ok {
+		return 0
+	}
+
+	return deadline.Sub(c.now())
+}
@@ -0,0 +1,60 @@
+package device
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCooldownsDisabled(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		c      = newCooldowns(0)
+	)
+
+	c.start(ID("mac:112233445566"))
+	assert.True(c.remaining(ID("mac:112233445566")) <= 0)
+	assert.Empty(c.deadline)
+}
+
+func TestCooldowns(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		now    = time.Now()
+		c      = newCooldowns(100 * time.Millisecond)
+		id     = ID("mac:112233445566")
+	)
+
+	c.now = func() time.Time { return now }
+	assert.True(c.remaining(id) <= 0)
+
+	c.start(id)
+	assert.Equal(100*time.Millisecond, c.remaining(id))
+	assert.True(c.remaining(ID("mac:665544332211")) <= 0)
+
+	now = now.Add(60 * time.Millisecond)
+	assert.Equal(40*time.Millisecond, c.remaining(id))
+
+	now = now.Add(60 * time.Millisecond)
+	assert.True(c.remaining(id) <= 0)
+
+	// the entry should be cleaned up once the period elapses
+	timeout := time.After(5 * time.Second)
+	for {
+		c.lock.Lock()
+		size := len(c.deadline)
+		c.lock.Unlock()
+
+		if size == 0 {
+			break
+		}
+
+		select {
+		case <-timeout:
+			assert.Fail("The cooldown entry was not removed")
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
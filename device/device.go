@@ -2,13 +2,17 @@ package device
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync/atomic"
 	"time"
 
+	"github.com/Comcast/webpa-common/convey"
 	"github.com/Comcast/webpa-common/logging"
+	"github.com/Comcast/webpa-common/wrp"
 	"github.com/go-kit/kit/log"
+	"github.com/gorilla/websocket"
 )
 
 const (
@@ -76,14 +80,35 @@ type Interface interface {
 	// the enclosing Manager instance.  The read pump will handle sending the response.
 	Send(*Request) (*Response, error)
 
-	// Statistics returns the current, tracked Statistics instance for this device
+	// Metadata returns the metadata captured from this device's connection request by
+	// Options.MetadataExtractor.  The returned map is a copy, so changing it has no effect
+	// on this device.  If no metadata was captured, the returned map is empty.
+	Metadata() map[string]string
+
+	// Context returns the context for this device's lifecycle.  For a device connected through a Manager,
+	// this context carries the values of the connecting request's context, such as a trace ID set by
+	// upstream middleware.  It is not cancelled when the connecting HTTP handler returns, but is cancelled
+	// exactly once when this device disconnects.
+	Context() context.Context
+
+	// ConnectedAt returns the time at which this device connected.  This is the same
+	// value as Statistics().ConnectedAt().
+	ConnectedAt() time.Time
+
+	// MetricsLabel returns the value used in place of this device's ID when labeling metrics.  For a device
+	// connected through a Manager, this is the ID hashed into one of Options.MetricsIDBuckets buckets.
+	MetricsLabel() string
+
+	// Statistics returns the current, tracked Statistics instance for this device.  The
+	// statistics are updated atomically, so reading them never blocks sending or receiving.
 	Statistics() Statistics
 }
 
 // device is the internal Interface implementation.  This type holds the internal
 // metadata exposed publicly, and provides some internal data structures for housekeeping.
 type device struct {
-	id ID
+	id         ID
+	remoteAddr string
 
 	errorLog log.Logger
 	infoLog  log.Logger
@@ -93,6 +118,35 @@ type device struct {
 
 	state int32
 
+	// closeCode and closeText are the contents of the close frame sent to the device.
+	// They are only written by the goroutine which closes this device, before shutdown is closed.
+	closeCode int
+	closeText string
+
+	// format is the wrp.Format used to encode messages sent to this device.
+	// It is accessed atomically, since it can be renegotiated while connected.
+	format int32
+
+	// lastPong is the time, in Unix nanoseconds, of the most recent pong from this device.
+	// It is zero until a pong is received, and is accessed atomically.
+	lastPong int64
+
+	// metadata is the convey data supplied by the device when it connected, if any.
+	// It is not modified after the device is connected.
+	metadata convey.C
+
+	// connectMetadata is the metadata extracted from the connection request.
+	// It is not modified after the device is connected.
+	connectMetadata map[string]string
+
+	// metricsLabel is this device's ID as labeled for metrics.  It is not modified after the device is connected.
+	metricsLabel string
+
+	// ctx is this device's lifecycle context, and cancel cancels it when this device disconnects.
+	// These are not modified after the device is connected.
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	shutdown     chan struct{}
 	messages     chan *envelope
 	transactions *Transactions
@@ -100,13 +154,17 @@ type device struct {
 
 // newDevice is an internal factory function for devices
 func newDevice(id ID, queueSize int, connectedAt time.Time, logger log.Logger) *device {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &device{
+		ctx:          ctx,
+		cancel:       cancel,
 		id:           id,
 		errorLog:     logging.Error(logger, "id", id),
 		infoLog:      logging.Info(logger, "id", id),
 		debugLog:     logging.Debug(logger, "id", id),
 		statistics:   NewStatistics(nil, connectedAt),
 		state:        stateOpen,
+		format:       int32(wrp.Msgpack),
 		shutdown:     make(chan struct{}),
 		messages:     make(chan *envelope, queueSize),
 		transactions: NewTransactions(),
@@ -132,11 +190,50 @@ func (d *device) MarshalJSON() ([]byte, error) {
 }
 
 func (d *device) requestClose() {
+	d.requestCloseCode(websocket.CloseNormalClosure, "close")
+}
+
+// requestCloseCode closes this device, sending the given close code and text to the device.
+// If this device is already closed, this method does nothing.
+func (d *device) requestCloseCode(code int, text string) {
 	if atomic.CompareAndSwapInt32(&d.state, stateOpen, stateClosed) {
+		d.closeCode = code
+		d.closeText = text
 		close(d.shutdown)
 	}
 }
 
+// bindContext replaces this device's lifecycle context with one that carries the values of the given
+// parent, but not its deadline or cancellation.  This method must be called before the device is
+// visible to other goroutines.
+func (d *device) bindContext(parent context.Context) {
+	d.cancel()
+	d.ctx, d.cancel = context.WithCancel(valuesContext{parent})
+}
+
+// encodeFormat returns the wrp.Format currently used to encode messages sent to this device
+func (d *device) encodeFormat() wrp.Format {
+	return wrp.Format(atomic.LoadInt32(&d.format))
+}
+
+// setEncodeFormat changes the wrp.Format used to encode subsequent messages sent to this device
+func (d *device) setEncodeFormat(f wrp.Format) {
+	atomic.StoreInt32(&d.format, int32(f))
+}
+
+// lastPongTime returns the time of the most recent pong, or the zero time if no pong has been received
+func (d *device) lastPongTime() time.Time {
+	if nanos := atomic.LoadInt64(&d.lastPong); nanos != 0 {
+		return time.Unix(0, nanos).UTC()
+	}
+
+	return time.Time{}
+}
+
+func (d *device) setLastPong(t time.Time) {
+	atomic.StoreInt64(&d.lastPong, t.UnixNano())
+}
+
 func (d *device) ID() ID {
 	return d.id
 }
@@ -154,7 +251,8 @@ func (d *device) Closed() bool {
 //
 // This function returns when either (1) the write pump has attempted to send the message to
 // the device, or (2) the request's context has been cancelled, which includes timing out.
-func (d *device) sendRequest(request *Request) error {
+// The returned flag is true only in the first case.
+func (d *device) sendRequest(request *Request) (bool, error) {
 	var (
 		done     = request.Context().Done()
 		complete = make(chan error, 1)
@@ -164,12 +262,20 @@ func (d *device) sendRequest(request *Request) error {
 		}
 	)
 
-	// attempt to enqueue the message
+	// attempt to enqueue the message.  the queue depth is adjusted beforehand, so that the
+	// write pump never observes a negative depth.
+	d.statistics.AddQueueDepth(1)
 	select {
 	case <-done:
-		return request.Context().Err()
+		d.statistics.AddQueueDepth(-1)
+		if len(d.messages) == cap(d.messages) {
+			d.statistics.AddDropped(1)
+		}
+
+		return false, request.Context().Err()
 	case <-d.shutdown:
-		return ErrorDeviceClosed
+		d.statistics.AddQueueDepth(-1)
+		return false, ErrorDeviceClosed
 	case d.messages <- envelope:
 	}
 
@@ -177,11 +283,35 @@ func (d *device) sendRequest(request *Request) error {
 	// or there's a result
 	select {
 	case <-done:
-		return request.Context().Err()
+		return false, request.Context().Err()
 	case <-d.shutdown:
-		return ErrorDeviceClosed
+		return false, ErrorDeviceClosed
 	case err := <-complete:
-		return err
+		return true, err
+	}
+}
+
+// trySend enqueues a request without waiting for room in the queue or for the write pump.  If the
+// device is closed or its queue is full, the request is dropped and this method returns false.
+// The request must not start a transaction, as nothing waits for a response.
+func (d *device) trySend(request *Request) bool {
+	if d.Closed() {
+		return false
+	}
+
+	envelope := &envelope{
+		request,
+		make(chan error, 1),
+	}
+
+	d.statistics.AddQueueDepth(1)
+	select {
+	case d.messages <- envelope:
+		return true
+	default:
+		d.statistics.AddQueueDepth(-1)
+		d.statistics.AddDropped(1)
+		return false
 	}
 }
 
@@ -204,8 +334,16 @@ func (d *device) awaitResponse(request *Request, result <-chan *Response) (*Resp
 }
 
 func (d *device) Send(request *Request) (*Response, error) {
+	response, _, err := d.send(request)
+	return response, err
+}
+
+// send is the implementation of Send.  The returned flag indicates whether the write pump finished
+// attempting to write the request, which distinguishes a device that never accepted the request
+// from one that accepted it but then failed to respond.
+func (d *device) send(request *Request) (*Response, bool, error) {
 	if d.Closed() {
-		return nil, ErrorDeviceClosed
+		return nil, false, ErrorDeviceClosed
 	}
 
 	var (
@@ -218,25 +356,69 @@ func (d *device) Send(request *Request) (*Response, error) {
 		if result, err = d.transactions.Register(transactionKey); err != nil {
 			// if a transaction key cannot be registered, we don't want to proceed.
 			// this indicates some larger problem, most often a duplicate transaction key.
-			return nil, err
+			return nil, false, err
 		}
 
 		// ensure that the transaction is cleared
 		defer d.transactions.Cancel(transactionKey)
 	}
 
-	if err := d.sendRequest(request); err != nil {
-		return nil, err
+	if written, err := d.sendRequest(request); err != nil {
+		return nil, written, err
 	}
 
 	if result == nil {
 		// if there is no pending transaction, we're done
-		return nil, nil
+		return nil, true, nil
 	}
 
-	return d.awaitResponse(request, result)
+	response, err := d.awaitResponse(request, result)
+	return response, true, err
+}
+
+func (d *device) Metadata() map[string]string {
+	copyOf := make(map[string]string, len(d.connectMetadata))
+	for key, value := range d.connectMetadata {
+		copyOf[key] = value
+	}
+
+	return copyOf
+}
+
+func (d *device) Context() context.Context {
+	return d.ctx
+}
+
+func (d *device) ConnectedAt() time.Time {
+	return d.statistics.ConnectedAt()
+}
+
+func (d *device) MetricsLabel() string {
+	return d.metricsLabel
 }
 
 func (d *device) Statistics() Statistics {
 	return d.statistics
 }
+
+// valuesContext exposes the values of its parent context, but never expires.  This allows a device
+// to outlive the HTTP request that connected it while still carrying that request's values.
+type valuesContext struct {
+	parent context.Context
+}
+
+func (vc valuesContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (vc valuesContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (vc valuesContext) Err() error {
+	return nil
+}
+
+func (vc valuesContext) Value(key interface{}) interface{} {
+	return vc.parent.Value(key)
+}
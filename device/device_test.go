@@ -62,6 +62,8 @@ func TestDevice(t *testing.T) {
 		assert.Equal(string(record.expectedID), device.String())
 		actualConnectedAt := device.Statistics().ConnectedAt()
 		assert.Equal(expectedConnectedAt, actualConnectedAt)
+		assert.Equal(actualConnectedAt, device.ConnectedAt())
+		assert.NotNil(device.Context())
 
 		assert.Equal(record.expectedID, device.ID())
 		assert.False(device.Closed())
@@ -76,7 +78,7 @@ func TestDevice(t *testing.T) {
 
 		assert.JSONEq(
 			fmt.Sprintf(
-				`{"id": "%s", "pending": 0, "statistics": {"duplications": 0, "bytesSent": 0, "messagesSent": 0, "bytesReceived": 0, "messagesReceived": 0, "connectedAt": "%s", "upTime": "%s"}}`,
+				`{"id": "%s", "pending": 0, "statistics": {"duplications": 0, "bytesSent": 0, "messagesSent": 0, "bytesReceived": 0, "messagesReceived": 0, "queueDepth": 0, "dropped": 0, "connectedAt": "%s", "upTime": "%s"}}`,
 				record.expectedID,
 				expectedConnectedAt.UTC().Format(time.RFC3339Nano),
 				expectedUpTime,
@@ -104,3 +106,71 @@ func TestDevice(t *testing.T) {
 		assert.Error(err)
 	}
 }
+
+func TestDeviceQueueStatistics(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		device  = newDevice(ID("test"), 1, time.Now(), logging.NewTestLogger(nil, t))
+		sent    = make(chan error, 1)
+	)
+
+	// with no write pump, the first message fills the queue and its sender waits for a result
+	go func() {
+		_, err := device.Send(&Request{Message: new(wrp.Message)})
+		sent <- err
+	}()
+
+	for device.Pending() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(1, device.Statistics().QueueDepth())
+	assert.Zero(device.Statistics().Dropped())
+
+	// the second message cannot be enqueued before its context times out, and so is dropped
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	response, err := device.Send((&Request{Message: new(wrp.Message)}).WithContext(ctx))
+	assert.Nil(response)
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Equal(1, device.Statistics().QueueDepth())
+	assert.Equal(1, device.Statistics().Dropped())
+
+	device.requestClose()
+	select {
+	case err := <-sent:
+		assert.Equal(ErrorDeviceClosed, err)
+	case <-time.After(5 * time.Second):
+		require.Fail("The first sender did not return after the device was closed")
+	}
+
+	// senders to a closed device are neither queued nor dropped
+	response, err = device.Send(&Request{Message: new(wrp.Message)})
+	assert.Nil(response)
+	assert.Equal(ErrorDeviceClosed, err)
+	assert.Equal(1, device.Statistics().QueueDepth())
+	assert.Equal(1, device.Statistics().Dropped())
+}
+
+func TestDeviceTrySend(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		device = newDevice(ID("test"), 1, time.Now(), logging.NewTestLogger(nil, t))
+	)
+
+	// with no write pump, the first message fills the queue without blocking
+	assert.True(device.trySend(&Request{Message: new(wrp.Message)}))
+	assert.Equal(1, device.Pending())
+	assert.Equal(1, device.Statistics().QueueDepth())
+	assert.Zero(device.Statistics().Dropped())
+
+	// a full queue drops the message rather than waiting
+	assert.False(device.trySend(&Request{Message: new(wrp.Message)}))
+	assert.Equal(1, device.Statistics().QueueDepth())
+	assert.Equal(1, device.Statistics().Dropped())
+
+	device.requestClose()
+	assert.False(device.trySend(&Request{Message: new(wrp.Message)}))
+	assert.Equal(1, device.Statistics().Dropped())
+}
@@ -33,7 +33,7 @@ type Listener struct {
 // health Dispatcher.
 func (l *Listener) OnDeviceEvent(e *device.Event) {
 	switch e.Type {
-	case device.Connect:
+	case device.Connect, device.Reconnect:
 		l.Dispatcher.SendEvent(func(s health.Stats) {
 			s[DeviceCount] += 1
 			s[TotalConnectionEvents] += 1
@@ -9,7 +9,7 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-func testListenerOnDeviceEventConnect(t *testing.T) {
+func testListenerOnDeviceEventConnect(t *testing.T, eventType device.EventType) {
 	var (
 		assert     = assert.New(t)
 		dispatcher = new(mockDispatcher)
@@ -29,7 +29,7 @@ func testListenerOnDeviceEventConnect(t *testing.T) {
 			hf(actualStats)
 		})
 
-	listener.OnDeviceEvent(&device.Event{Type: device.Connect})
+	listener.OnDeviceEvent(&device.Event{Type: eventType})
 	assert.Equal(expectedStats, actualStats)
 
 	dispatcher.AssertExpectations(t)
@@ -142,7 +142,8 @@ func testListenerOnDeviceEventPong(t *testing.T) {
 
 func TestListener(t *testing.T) {
 	t.Run("OnDeviceEvent", func(t *testing.T) {
-		t.Run("Connect", testListenerOnDeviceEventConnect)
+		t.Run("Connect", func(t *testing.T) { testListenerOnDeviceEventConnect(t, device.Connect) })
+		t.Run("Reconnect", func(t *testing.T) { testListenerOnDeviceEventConnect(t, device.Reconnect) })
 		t.Run("Disconnect", testListenerOnDeviceEventDisconnect)
 		t.Run("TransactionComplete", testListenerOnDeviceEventTransactionComplete)
 		t.Run("Ping", testListenerOnDeviceEventPing)
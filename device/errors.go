@@ -21,4 +21,9 @@ var (
 	ErrorResponseNoContents           = errors.New("The response has no contents")
 	ErrorDeviceBusy                   = errors.New("That device is busy")
 	ErrorDeviceClosed                 = errors.New("That device has been closed")
+	ErrorReconnectCooldown            = errors.New("That device must wait before reconnecting")
+	ErrorUnsupportedFormat            = errors.New("That WRP format is not supported")
+	ErrorDeviceLimitReached           = errors.New("The maximum number of devices are connected")
+	ErrorShuttingDown                 = errors.New("The device manager is shutting down")
+	ErrorDeviceIdle                   = errors.New("The device sent nothing within the idle period")
 )
@@ -0,0 +1,59 @@
+package device
+
+import (
+	"strings"
+
+	"github.com/Comcast/webpa-common/wrp"
+)
+
+const (
+	// EventStreamDestinationPrefix is the prefix of the destination of each message produced by an EventStreamListener.
+	// The full destination is "event:device-status/{device id}/{event type}", where the event type is lowercase.
+	EventStreamDestinationPrefix = "event:device-status/"
+
+	// EventStreamDeviceIDKey is the metadata key holding the device identifier of a streamed event
+	EventStreamDeviceIDKey = "device-id"
+
+	// EventStreamEventTypeKey is the metadata key holding the EventType, as with EventType.String, of a streamed event
+	EventStreamEventTypeKey = "event-type"
+
+	// EventStreamDataKey is the metadata key holding the pong data of a streamed Pong event.
+	// It is omitted when the pong carried no data.
+	EventStreamDataKey = "data"
+)
+
+// EventStreamListener produces a Listener that publishes Connect, Reconnect, Disconnect, and Pong events
+// as WRP SimpleEvent messages.  Each message has the given source, a destination beginning with
+// EventStreamDestinationPrefix, and describes its event through metadata.  Other events are ignored.
+//
+// The sink is invoked synchronously within the listener, so it should not block.  Errors returned by the
+// sink are ignored, as listeners have no way to report them.
+func EventStreamListener(sink func(*wrp.Message) error, source string) Listener {
+	return func(e *Event) {
+		switch e.Type {
+		case Connect, Reconnect, Disconnect, Pong:
+		default:
+			return
+		}
+
+		var (
+			id        = e.Device.ID()
+			eventType = e.Type.String()
+			message   = &wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      source,
+				Destination: EventStreamDestinationPrefix + string(id) + "/" + strings.ToLower(eventType),
+				Metadata: map[string]string{
+					EventStreamDeviceIDKey:  string(id),
+					EventStreamEventTypeKey: eventType,
+				},
+			}
+		)
+
+		if e.Type == Pong && len(e.Data) > 0 {
+			message.Metadata[EventStreamDataKey] = e.Data
+		}
+
+		sink(message)
+	}
+}
@@ -0,0 +1,79 @@
+package device
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Comcast/webpa-common/wrp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventStreamListener(t *testing.T) {
+	var (
+		assert   = assert.New(t)
+		id       = IntToMAC(0x112233445566)
+		device   = new(mockDevice)
+		messages []*wrp.Message
+
+		listener = EventStreamListener(
+			func(m *wrp.Message) error {
+				messages = append(messages, m)
+				return errors.New("sink errors are ignored")
+			},
+			"dns:talaria.example.com",
+		)
+	)
+
+	device.On("ID").Return(id)
+
+	listener(&Event{Type: Connect, Device: device})
+	listener(&Event{Type: MessageSent, Device: device})
+	listener(&Event{Type: Pong, Device: device, Data: "pong data"})
+	listener(&Event{Type: Disconnect, Device: device})
+	listener(&Event{Type: Reconnect, Device: device})
+
+	assert.Equal(
+		[]*wrp.Message{
+			{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:talaria.example.com",
+				Destination: "event:device-status/mac:112233445566/connect",
+				Metadata: map[string]string{
+					EventStreamDeviceIDKey:  "mac:112233445566",
+					EventStreamEventTypeKey: "Connect",
+				},
+			},
+			{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:talaria.example.com",
+				Destination: "event:device-status/mac:112233445566/pong",
+				Metadata: map[string]string{
+					EventStreamDeviceIDKey:  "mac:112233445566",
+					EventStreamEventTypeKey: "Pong",
+					EventStreamDataKey:      "pong data",
+				},
+			},
+			{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:talaria.example.com",
+				Destination: "event:device-status/mac:112233445566/disconnect",
+				Metadata: map[string]string{
+					EventStreamDeviceIDKey:  "mac:112233445566",
+					EventStreamEventTypeKey: "Disconnect",
+				},
+			},
+			{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:talaria.example.com",
+				Destination: "event:device-status/mac:112233445566/reconnect",
+				Metadata: map[string]string{
+					EventStreamDeviceIDKey:  "mac:112233445566",
+					EventStreamEventTypeKey: "Reconnect",
+				},
+			},
+		},
+		messages,
+	)
+
+	device.AssertExpectations(t)
+}
@@ -178,6 +178,10 @@ func (mh *MessageHandler) ServeHTTP(httpResponse http.ResponseWriter, httpReques
 			code = http.StatusBadRequest
 		}
 
+		if _, invalid := err.(*wrp.ValidationError); invalid {
+			code = http.StatusBadRequest
+		}
+
 		httperror.Formatf(
 			httpResponse,
 			code,
@@ -517,6 +517,7 @@ func TestMessageHandler(t *testing.T) {
 			testMessageHandlerServeHTTPRouteError(t, ErrorNonUniqueID, http.StatusBadRequest)
 			testMessageHandlerServeHTTPRouteError(t, ErrorInvalidTransactionKey, http.StatusBadRequest)
 			testMessageHandlerServeHTTPRouteError(t, ErrorTransactionAlreadyRegistered, http.StatusBadRequest)
+			testMessageHandlerServeHTTPRouteError(t, &wrp.ValidationError{Field: "dest", Reason: "test"}, http.StatusBadRequest)
 			testMessageHandlerServeHTTPRouteError(t, errors.New("random error"), http.StatusInternalServerError)
 		})
 
@@ -3,10 +3,11 @@ package device
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"net/http"
-	"regexp"
-	"strings"
-	"unicode"
+	"strconv"
+
+	"github.com/Comcast/webpa-common/wrp"
 )
 
 // ID represents a normalized identifer for a device.
@@ -17,22 +18,7 @@ func (id ID) Bytes() []byte {
 	return []byte(id)
 }
 
-const (
-	hexDigits     = "0123456789abcdefABCDEF"
-	macDelimiters = ":-.,"
-	macPrefix     = "mac"
-	macLength     = 12
-)
-
-var (
-	invalidID = ID("")
-
-	// idPattern is the precompiled regular expression that all device identifiers must match.
-	// Matching is partial, as everything after the service is ignored.
-	idPattern = regexp.MustCompile(
-		`^(?P<prefix>(?i)mac|uuid|dns|serial):(?P<id>[^/]+)(?P<service>/[^/]+)?`,
-	)
-)
+var invalidID = ID("")
 
 // IntToMAC accepts a 64-bit integer and formats that as a device MAC address identifier
 // The returned ID will be of the form mac:XXXXXXXXXXXX, where X is a hexadecimal digit using
@@ -41,41 +27,21 @@ func IntToMAC(value uint64) ID {
 	return ID(fmt.Sprintf("mac:%012x", value&0x0000FFFFFFFFFFFF))
 }
 
-// ParseID parses a raw device name into a canonicalized identifier.
+// ParseID parses a raw device name into a canonicalized identifier.  Device names are WRP locators,
+// as parsed by wrp.ParseLocator, using one of the mac, uuid, dns, or serial schemes.  Anything after the
+// device's identifier, such as a service, is ignored.
 func ParseID(deviceName string) (ID, error) {
-	match := idPattern.FindStringSubmatch(deviceName)
-	if match == nil {
+	scheme, id, err := wrp.ParseLocator(deviceName)
+	if err != nil {
 		return invalidID, ErrorInvalidDeviceName
 	}
 
-	var (
-		prefix = strings.ToLower(match[1])
-		idPart = match[2]
-	)
-
-	if prefix == macPrefix {
-		var invalidCharacter rune = -1
-		idPart = strings.Map(
-			func(r rune) rune {
-				switch {
-				case strings.ContainsRune(hexDigits, r):
-					return unicode.ToLower(r)
-				case strings.ContainsRune(macDelimiters, r):
-					return -1
-				default:
-					invalidCharacter = r
-					return -1
-				}
-			},
-			idPart,
-		)
-
-		if invalidCharacter != -1 || len(idPart) != macLength {
-			return invalidID, ErrorInvalidDeviceName
-		}
+	switch scheme {
+	case wrp.MacScheme, wrp.UUIDScheme, wrp.DNSScheme, wrp.SerialScheme:
+		return ID(scheme + ":" + id), nil
+	default:
+		return invalidID, ErrorInvalidDeviceName
 	}
-
-	return ID(fmt.Sprintf("%s:%s", prefix, idPart)), nil
 }
 
 // ContextKey is the key type used by information stored in Contexts from this package
@@ -123,3 +89,29 @@ func IDHashParser(request *http.Request) ([]byte, error) {
 
 	return id.Bytes(), nil
 }
+
+// IDLabeler produces the value used to label metrics with a device ID.  Raw device IDs
+// are generally unsuitable as label values, since they both expose device identity and
+// produce unbounded label cardinality.
+//
+// A Manager labels each device it connects with an IDLabeler built from Options.MetricsIDBuckets.
+// Listeners that emit device-labeled metrics should use Interface.MetricsLabel rather than raw device IDs.
+type IDLabeler func(ID) string
+
+// NewIDLabeler returns an IDLabeler that hashes device IDs into the given number of buckets.
+// The returned labels are the decimal bucket numbers, from 0 to buckets-1, and a given ID
+// always maps to the same bucket.  If buckets is nonpositive, the returned IDLabeler simply
+// returns the raw device ID.
+func NewIDLabeler(buckets int) IDLabeler {
+	if buckets < 1 {
+		return func(id ID) string {
+			return string(id)
+		}
+	}
+
+	return func(id ID) string {
+		hash := fnv.New32a()
+		hash.Write(id.Bytes())
+		return strconv.FormatUint(uint64(hash.Sum32()%uint32(buckets)), 10)
+	}
+}
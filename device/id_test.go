@@ -2,6 +2,7 @@ package device
 
 import (
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,6 +48,9 @@ func TestParseID(t *testing.T) {
 		{"invalid:a-BB-44-55", "", true},
 		{"mac:11-aa-BB-44-55", "", true},
 		{"MAC:invalid45566", "", true},
+		{"event:device-status", "", true},
+		{"self:/config", "", true},
+		{"dns:", "", true},
 	}
 
 	for _, record := range testData {
@@ -99,3 +103,44 @@ func TestIDHashParser(t *testing.T) {
 		}
 	}
 }
+
+func TestNewIDLabeler(t *testing.T) {
+	t.Run("Raw", func(t *testing.T) {
+		assert := assert.New(t)
+		for _, buckets := range []int{-1, 0} {
+			labeler := NewIDLabeler(buckets)
+			for _, id := range testDeviceIDs {
+				assert.Equal(string(id), labeler(id))
+			}
+		}
+	})
+
+	t.Run("Bucketed", func(t *testing.T) {
+		for _, buckets := range []int{1, 2, 7, 64} {
+			t.Run(strconv.Itoa(buckets), func(t *testing.T) {
+				var (
+					assert  = assert.New(t)
+					labeler = NewIDLabeler(buckets)
+					labels  = make(map[string]bool)
+				)
+
+				for i := uint64(0); i < 1000; i++ {
+					var (
+						id    = IntToMAC(i * 0x10203)
+						label = labeler(id)
+					)
+
+					assert.Equal(label, labeler(id), "the same ID must always map to the same bucket")
+
+					bucket, err := strconv.Atoi(label)
+					assert.NoError(err)
+					assert.True(bucket >= 0 && bucket < buckets)
+					labels[label] = true
+				}
+
+				assert.True(len(labels) <= buckets)
+				assert.Len(labels, buckets, "1000 IDs should hit every bucket")
+			})
+		}
+	})
+}
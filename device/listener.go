@@ -16,11 +16,13 @@ const (
 	// Device can no longer receive requests.
 	Disconnect
 
-	// MessageSent indicates that a message was successfully dispatched to a device.
+	// MessageSent indicates that a message was successfully dispatched to a device.  The event's
+	// Message is the message that was written.
 	MessageSent
 
 	// MessageReceived indicates that a message has been successfully received and
 	// dispatched to any goroutine waiting on it, as would be the case for a response.
+	// The event's Message is the decoded *wrp.Message, and Contents holds the raw frame.
 	MessageReceived
 
 	// MessageFailed indicates that a message could not be sent to a device, either because
@@ -42,6 +44,12 @@ const (
 	// Pong occurs when a device has responded to a ping
 	Pong
 
+	// Reconnect is dispatched instead of Connect when a device connects with the same ID as a device
+	// that is still connected, or that disconnected within Options.ReconnectGracePeriod.  It has the
+	// same guarantees as Connect, and the replaced device, if still connected, is closed and receives
+	// its own Disconnect event.  Reconnect events are only dispatched when a grace period is configured.
+	Reconnect
+
 	InvalidEventString string = "!!INVALID DEVICE EVENT TYPE!!"
 )
 
@@ -66,8 +74,12 @@ func (et EventType) String() string {
 		return "TransactionComplete"
 	case TransactionBroken:
 		return "TransactionBroken"
+	case Ping:
+		return "Ping"
 	case Pong:
 		return "Pong"
+	case Reconnect:
+		return "Reconnect"
 	default:
 		return InvalidEventString
 	}
@@ -110,6 +122,10 @@ type Event struct {
 
 	// Data is the ping or pong data associated with this event.  This field is only set for Ping and Pong events.
 	Data string
+
+	// Reason describes why a device disconnected, exactly as with AuditRecord.Reason, e.g. DisconnectReasonIdle.
+	// This field is only set for Disconnect events.
+	Reason string
 }
 
 // Clear resets all fields in this Event.  This is most often in preparation to reuse the Event instance.
@@ -166,4 +182,15 @@ func (e *Event) SetPong(d Interface, data string) {
 // Listener is an event sink.  Listeners should never modify events and should never
 // store events for later use.  If data from an event is needed for another goroutine
 // or for long-term storage, a copy should be made.
+//
+// Listeners are invoked synchronously by the goroutines that read from and write to each device,
+// so a listener must never block.  Any expensive work, such as updating remote metrics, should be
+// handed off to another goroutine.
+//
+// For any given device, the Connect or Reconnect event is always dispatched before any other event.  Message,
+// Ping, and Pong events are dispatched from two goroutines, one reading and one writing, so events
+// from reading and writing are not ordered relative to each other.  Disconnect is dispatched once
+// the device has been removed from its Manager.  It may still be followed by a MessageFailed event
+// for each message that was waiting to be written, and by an event for a read or write that was
+// already in progress.
 type Listener func(*Event)
@@ -20,7 +20,9 @@ func testEventString(t *testing.T) {
 			MessageFailed,
 			TransactionComplete,
 			TransactionBroken,
+			Ping,
 			Pong,
+			Reconnect,
 		}
 	)
 
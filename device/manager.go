@@ -2,9 +2,14 @@ package device
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Comcast/webpa-common/convey/conveyhttp"
@@ -14,6 +19,9 @@ import (
 	"github.com/go-kit/kit/log"
 )
 
+// shutdownPollInterval is how often Shutdown checks whether all devices have disconnected
+const shutdownPollInterval = 10 * time.Millisecond
+
 var (
 	authStatus = &wrp.AuthorizationStatus{Status: wrp.AuthStatusAuthorized}
 
@@ -51,14 +59,45 @@ type Connector interface {
 	// No methods on this Manager should be called from within the predicate function, or
 	// a deadlock will likely occur.
 	DisconnectIf(func(ID) bool) int
+
+	// DisconnectBatch disconnects each device whose ID is in the given slice, returning the number of
+	// devices actually disconnected.  Duplicate IDs are only disconnected once, and IDs that are not
+	// connected are skipped.  Unlike DisconnectIf, each ID is looked up directly, so this method is
+	// much cheaper when only a few devices out of many need to be disconnected.
+	DisconnectBatch([]ID) int
 }
 
+const (
+	// DeliveryResponseDelivered is the rdr value of a delivery response for a message that
+	// was successfully sent to its device
+	DeliveryResponseDelivered int64 = 1
+
+	// DeliveryResponseFailed is the rdr value of a delivery response for a message that
+	// could not be sent to its device, e.g. because the device was not connected
+	DeliveryResponseFailed int64 = 0
+)
+
 // Router handles dispatching messages to devices.
 type Router interface {
 	// Route dispatches a WRP request to exactly one device, identified by the ID
 	// field of the request.  Route is synchronous, and honors the cancellation semantics
 	// of the Request's context.
+	//
+	// If delivery responses are enabled via Options.DeliveryResponses, a request which does not
+	// start a transaction produces a Response addressed back to the request's source whose rdr
+	// is either DeliveryResponseDelivered or DeliveryResponseFailed.  When delivery fails,
+	// the error is returned along with that Response.
+	//
+	// At most one device is connected for any ID, since the newest connection always replaces
+	// an existing device with the same ID.  Route therefore never returns ErrorNonUniqueID.
 	Route(*Request) (*Response, error)
+
+	// RouteContext is like Route, except that the given context bounds the request, replacing any
+	// context the request already had.  If the context ends before the device has accepted and written
+	// the request, ErrorDeviceBusy is returned instead of the context's error.  This lets callers
+	// bound the time spent on a device that has stopped draining its queue.  If the context ends
+	// while awaiting a transaction's response, the context's error is returned.
+	RouteContext(context.Context, *Request) (*Response, error)
 }
 
 // Registry is the strategy interface for querying the set of connected devices.  Methods
@@ -73,11 +112,23 @@ type Registry interface {
 	// or the visitor, or a deadlock will most definitely occur.
 	VisitIf(func(ID) bool, func(Interface)) int
 
+	// VisitWhere applies a visitor to any device matching the device predicate.  Unlike VisitIf,
+	// the predicate receives the whole device, so that devices can be selected by properties other
+	// than their ID.  As with VisitIf, the number of matching devices is returned.
+	//
+	// No methods on this Manager should be called from within either the predicate
+	// or the visitor, or a deadlock will most definitely occur.
+	VisitWhere(func(Interface) bool, func(Interface)) int
+
 	// VisitAll applies the given visitor function to each device known to this manager.
 	//
 	// No methods on this Manager should be called from within the visitor function, or
 	// a deadlock will likely occur.
 	VisitAll(func(Interface)) int
+
+	// Len returns the number of devices currently known to this manager.  This is much cheaper
+	// than counting devices with VisitAll, as no devices are visited.
+	Len() int
 }
 
 // Manager supplies a hub for connecting and disconnecting devices as well as
@@ -86,6 +137,49 @@ type Manager interface {
 	Connector
 	Router
 	Registry
+
+	// Replay delivers up to the last n events dispatched by this manager to the given listener,
+	// oldest first.  This allows a listener that was not configured when this manager was created,
+	// such as an administrative stream, to bootstrap its view of the current devices.  Only events
+	// retained via Options.EventReplaySize are available for replay.
+	//
+	// The replayed events are copies, but any Message or Contents they refer to are shared and
+	// must not be modified.
+	Replay(Listener, int)
+
+	// Subscribe replays up to the last n events to the given listener, exactly as with Replay, and then
+	// registers that listener to receive every event dispatched afterward.  Replaying and registering happen
+	// atomically with respect to dispatch, so the listener neither misses nor receives twice any event
+	// dispatched around the call.  Replayed events are delivered before any subsequent event.
+	//
+	// The returned function unregisters the listener.  It is idempotent.  The listener must not call
+	// Subscribe, nor the returned function, from within itself.
+	Subscribe(Listener, int) func()
+
+	// SignalBackpressure tells each of the given devices to back off as described by the directive.
+	// Devices are either sent a backpressure message or disconnected with BackpressureCloseCode.
+	// This method never blocks: backpressure messages are enqueued without waiting, and a device whose
+	// queue is full has the message dropped and counted in its statistics.  The count of devices which
+	// were signaled is returned.
+	SignalBackpressure([]ID, BackpressureDirective) int
+
+	// DeviceState returns a snapshot of the current state of the device with the given identifier.
+	// If no such device is connected, ErrorDeviceNotFound is returned.
+	DeviceState(ID) (DeviceStateSnapshot, error)
+
+	// Shutdown drains this manager.  New connections are rejected with a 503 and ErrorShuttingDown from
+	// the moment this method is called, and remain rejected afterward.  Every connected device is sent a
+	// normal websocket closure, and this method waits until all devices have disconnected or the context
+	// is done, whichever comes first.  Disconnect events are dispatched for each device as usual.
+	//
+	// If the context is done first, any devices that have not yet disconnected are removed from this manager,
+	// and the number of such forcibly closed devices is returned along with the context's error.
+	Shutdown(context.Context) (int, error)
+
+	// SetFormat changes the wrp.Format used to encode messages subsequently routed to
+	// the device with the given ID.  This supports devices which renegotiate their format
+	// mid-session.  If no such device is connected, ErrorDeviceNotFound is returned.
+	SetFormat(ID, wrp.Format) error
 }
 
 // NewManager constructs a Manager from a set of options.  A ConnectionFactory will be
@@ -103,12 +197,27 @@ func NewManager(o *Options, cf ConnectionFactory) Manager {
 
 		connectionFactory:      cf,
 		conveyTranslator:       conveyhttp.NewHeaderTranslator("", nil),
-		registry:               newRegistry(o.initialCapacity()),
+		registry:               newRegistry(o.initialCapacity(), o.maxDevices()),
 		deviceMessageQueueSize: o.deviceMessageQueueSize(),
 		pingPeriod:             o.pingPeriod(),
 		authDelay:              o.authDelay(),
+		deliveryResponses:      o.deliveryResponses(),
+		validateMessages:       o.validateMessages(),
+		metadataExtractor:      o.metadataExtractor(),
+		idLabeler:              NewIDLabeler(o.metricsIDBuckets()),
+		encoderPools:           make(map[wrp.Format]*wrp.EncoderPool, len(wrp.AllFormats())),
+
+		cooldowns:                 newCooldowns(o.reconnectCooldown()),
+		cooldownServerDisconnects: o.cooldownServerDisconnects(),
+		reconnectGrace:            newCooldowns(o.reconnectGracePeriod()),
 
 		listeners: o.listeners(),
+		auditSink: o.auditSink(),
+		replay:    newEventBuffer(o.eventReplaySize()),
+	}
+
+	for _, f := range wrp.AllFormats() {
+		m.encoderPools[f] = wrp.NewEncoderPool(o.encoderPoolSize(), f)
 	}
 
 	return m
@@ -128,8 +237,29 @@ type manager struct {
 	deviceMessageQueueSize int
 	pingPeriod             time.Duration
 	authDelay              time.Duration
+	deliveryResponses      bool
+	validateMessages       bool
+	metadataExtractor      func(*http.Request) map[string]string
+	idLabeler              IDLabeler
+	encoderPools           map[wrp.Format]*wrp.EncoderPool
+
+	cooldowns                 *cooldowns
+	cooldownServerDisconnects bool
+
+	// reconnectGrace tracks recently disconnected device IDs, so that their next connection is a Reconnect
+	reconnectGrace *cooldowns
 
 	listeners []Listener
+	auditSink AuditSink
+	replay    *eventBuffer
+
+	// subscribeLock guards both the replay buffer and subscribers, so that Subscribe sees each event
+	// either in the replay buffer or as a subscriber, but never both.  subscribers is copied on write.
+	subscribeLock sync.Mutex
+	subscribers   []*subscriber
+
+	// shuttingDown is nonzero once Shutdown has been called.  It is accessed atomically.
+	shuttingDown int32
 }
 
 func (m *manager) Connect(response http.ResponseWriter, request *http.Request, responseHeader http.Header) (Interface, error) {
@@ -145,37 +275,125 @@ func (m *manager) Connect(response http.ResponseWriter, request *http.Request, r
 		return nil, ErrorMissingDeviceNameContext
 	}
 
+	if m.isShuttingDown() {
+		httperror.Format(
+			response,
+			http.StatusServiceUnavailable,
+			ErrorShuttingDown,
+		)
+
+		return nil, ErrorShuttingDown
+	}
+
+	if remaining := m.cooldowns.remaining(id); remaining > 0 {
+		response.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		httperror.Format(
+			response,
+			http.StatusTooManyRequests,
+			ErrorReconnectCooldown,
+		)
+
+		return nil, ErrorReconnectCooldown
+	}
+
+	// this check allows the common case of a full registry to be rejected with an HTTP status.  The
+	// registry enforces the limit atomically when the device is added, after the websocket upgrade.
+	if _, exists := m.registry.get(id); !exists && m.registry.full() {
+		httperror.Format(
+			response,
+			http.StatusServiceUnavailable,
+			ErrorDeviceLimitReached,
+		)
+
+		return nil, ErrorDeviceLimitReached
+	}
+
 	c, err := m.connectionFactory.NewConnection(response, request, responseHeader)
 	if err != nil {
 		return nil, err
 	}
 
 	var (
-		d         = newDevice(id, m.deviceMessageQueueSize, time.Now(), m.logger)
-		closeOnce = new(sync.Once)
+		connectedAt = time.Now()
+		d           = newDevice(id, m.deviceMessageQueueSize, connectedAt, m.logger)
+		closeOnce   = new(sync.Once)
 	)
 
+	// all metadata must be in place before the device is visible to other goroutines
+	d.remoteAddr = request.RemoteAddr
+	d.metricsLabel = m.idLabeler(id)
+	d.bindContext(request.Context())
+	if m.metadataExtractor != nil {
+		extracted := m.metadataExtractor(request)
+		d.connectMetadata = make(map[string]string, len(extracted))
+		for key, value := range extracted {
+			d.connectMetadata[key] = value
+		}
+	}
+
 	if c, err := m.conveyTranslator.FromHeader(request.Header); err == nil {
 		m.debugLog.Log("convey", c)
+		d.metadata = c
 	} else if err != conveyhttp.ErrMissingHeader {
 		m.errorLog.Log(logging.MessageKey(), "badly formatted convey data", logging.ErrorKey(), err)
 	}
 
-	go m.readPump(d, c, closeOnce)
-	go m.writePump(d, c, closeOnce)
-	if existing := m.registry.add(d); existing != nil {
+	recentlyDisconnected := m.reconnectGrace.remaining(id) > 0
+	existing, err := m.registry.add(d)
+	if err != nil {
+		// the websocket upgrade has already happened, so the device is told to try again later with a close frame
+		d.errorLog.Log(logging.MessageKey(), "rejecting device connection", logging.ErrorKey(), err)
+		c.SendCloseCode(BackpressureCloseCode, err.Error())
+		c.Close()
+		d.cancel()
+		return nil, err
+	}
+
+	if existing != nil {
 		existing.errorLog.Log(logging.MessageKey(), "disconnecting duplicate device")
 		existing.requestClose()
 		d.statistics.AddDuplications(existing.statistics.Duplications() + 1)
 	}
 
+	// a device that raced with Shutdown is closed as soon as its pumps start
+	if m.isShuttingDown() {
+		d.requestClose()
+	}
+
+	// audit the connection before the pumps start, so that it always precedes the disconnection
+	m.auditSink.Connected(AuditRecord{
+		ID:         id,
+		RemoteAddr: d.remoteAddr,
+		Timestamp:  connectedAt,
+	})
+
+	// a device replacing an existing one, or returning within the grace period, is reported as a Reconnect.
+	// the event is dispatched before the pumps start, so that it precedes all other events for this device.
+	eventType := Connect
+	if m.reconnectGrace.period > 0 && (existing != nil || recentlyDisconnected) {
+		eventType = Reconnect
+	}
+
+	m.dispatch(&Event{Type: eventType, Device: d})
+
+	go m.readPump(d, c, closeOnce)
+	go m.writePump(d, c, closeOnce)
 	return d, nil
 }
 
 func (m *manager) dispatch(e *Event) {
+	m.subscribeLock.Lock()
+	m.replay.add(e)
+	subscribers := m.subscribers
+	m.subscribeLock.Unlock()
+
 	for _, listener := range m.listeners {
 		listener(e)
 	}
+
+	for _, s := range subscribers {
+		s.listener(e)
+	}
 }
 
 // pumpClose handles the proper shutdown and logging of a device's pumps.
@@ -192,20 +410,46 @@ func (m *manager) pumpClose(d *device, c Connection, pumpError error) {
 		d.debugLog.Log(logging.MessageKey(), "pump close")
 	}
 
-	m.registry.remove(d)
+	// if the device is already closed, the server requested the disconnection
+	serverDisconnect := d.Closed()
+
+	// a device replaced by a newer connection with the same ID must not put that ID into cooldown
+	if superseded := m.registry.remove(d); !superseded && (!serverDisconnect || m.cooldownServerDisconnects) {
+		m.cooldowns.start(d.id)
+	}
+
+	m.reconnectGrace.start(d.id)
 
 	// always request a close, to ensure that the write goroutine is
 	// shutdown and to signal to other goroutines that the device is closed
 	d.requestClose()
+	d.cancel()
 
 	if closeError := c.Close(); closeError != nil {
 		d.debugLog.Log(logging.MessageKey(), "Error closing device connection", logging.ErrorKey(), closeError)
 	}
 
+	reason := DisconnectReasonDevice
+	if serverDisconnect {
+		reason = DisconnectReasonServer
+	} else if pumpError == ErrorDeviceIdle {
+		reason = DisconnectReasonIdle
+	} else if pumpError != nil {
+		reason = pumpError.Error()
+	}
+
+	m.auditSink.Disconnected(AuditRecord{
+		ID:         d.id,
+		RemoteAddr: d.remoteAddr,
+		Timestamp:  time.Now(),
+		Reason:     reason,
+	})
+
 	m.dispatch(
 		&Event{
 			Type:   Disconnect,
 			Device: d,
+			Reason: reason,
 		},
 	)
 }
@@ -217,6 +461,7 @@ func (m *manager) pongCallbackFor(d *device) func(string) {
 	event := new(Event)
 
 	return func(data string) {
+		d.setLastPong(time.Now())
 		event.SetPong(d, data)
 		m.dispatch(event)
 	}
@@ -243,6 +488,11 @@ func (m *manager) readPump(d *device, c Connection, closeOnce *sync.Once) {
 		var frameBuffer bytes.Buffer
 		frameRead, readError = c.Read(&frameBuffer)
 		if readError != nil {
+			if netError, ok := readError.(net.Error); ok && netError.Timeout() {
+				// the read deadline is only exceeded when the device has been silent for the idle period
+				readError = ErrorDeviceIdle
+			}
+
 			return
 		} else if !frameRead {
 			d.errorLog.Log(logging.MessageKey(), "skipping unsupported frame")
@@ -298,10 +548,9 @@ func (m *manager) writePump(d *device, c Connection, closeOnce *sync.Once) {
 
 	var (
 		// we'll reuse this event instance
-		event = Event{Type: Connect, Device: d}
+		event Event
 
 		envelope   *envelope
-		encoder    = wrp.NewEncoder(nil, wrp.Msgpack)
 		writeError error
 
 		pingData    = fmt.Sprintf("ping[%s]", d.id)
@@ -317,8 +566,6 @@ func (m *manager) writePump(d *device, c Connection, closeOnce *sync.Once) {
 		})
 	)
 
-	m.dispatch(&event)
-
 	// cleanup: we not only ensure that the device and connection are closed but also
 	// ensure that any messages that were waiting and/or failed are dispatched to
 	// the configured listener
@@ -342,6 +589,7 @@ func (m *manager) writePump(d *device, c Connection, closeOnce *sync.Once) {
 		for {
 			select {
 			case undeliverable := <-d.messages:
+				d.statistics.AddQueueDepth(-1)
 				d.errorLog.Log(logging.MessageKey(), "undeliverable message", "deviceMessage", undeliverable)
 				event.SetRequestFailed(d, undeliverable.request, writeError)
 				m.dispatch(&event)
@@ -356,23 +604,32 @@ func (m *manager) writePump(d *device, c Connection, closeOnce *sync.Once) {
 
 		select {
 		case <-d.shutdown:
-			writeError = c.SendClose()
+			writeError = c.SendCloseCode(d.closeCode, d.closeText)
 			return
 
 		case envelope = <-d.messages:
-			var frameContents []byte
-			if envelope.request.Format == wrp.Msgpack && len(envelope.request.Contents) > 0 {
+			d.statistics.AddQueueDepth(-1)
+			var (
+				frameContents []byte
+				format        = d.encodeFormat()
+			)
+
+			if envelope.request.Format == format && len(envelope.request.Contents) > 0 {
 				frameContents = envelope.request.Contents
 			} else {
-				// if the request was in a format other than Msgpack, or if the caller did not pass
+				// if the request was in a format other than the device's, or if the caller did not pass
 				// Contents, then do the encoding here.
-				encoder.ResetBytes(&frameContents)
-				writeError = encoder.Encode(envelope.request.Message)
+				writeError = m.encoderPools[format].EncodeBytes(&frameContents, envelope.request.Message)
 			}
 
 			if writeError == nil {
-				var bytesSent int
-				if bytesSent, writeError = c.Write(frameContents); writeError == nil {
+				// a caller's deadline bounds the write, in addition to the configured write timeout
+				var (
+					deadline, _ = envelope.request.Context().Deadline()
+					bytesSent   int
+				)
+
+				if bytesSent, writeError = c.WriteBefore(frameContents, deadline); writeError == nil {
 					d.statistics.AddBytesSent(bytesSent)
 					d.statistics.AddMessagesSent(1)
 				}
@@ -419,6 +676,12 @@ func (m *manager) DisconnectIf(filter func(ID) bool) int {
 	})
 }
 
+func (m *manager) DisconnectBatch(ids []ID) int {
+	return m.registry.removeIDs(ids, func(d *device) {
+		d.requestClose()
+	})
+}
+
 func (m *manager) Get(id ID) (Interface, bool) {
 	return m.registry.get(id)
 }
@@ -427,16 +690,220 @@ func (m *manager) VisitIf(filter func(ID) bool, visitor func(Interface)) int {
 	return m.registry.visitIf(filter, m.wrapVisitor(visitor))
 }
 
+func (m *manager) Len() int {
+	return m.registry.len()
+}
+
+func (m *manager) VisitWhere(predicate func(Interface) bool, visitor func(Interface)) int {
+	return m.registry.visitWhere(
+		func(d *device) bool { return predicate(d) },
+		m.wrapVisitor(visitor),
+	)
+}
+
 func (m *manager) VisitAll(visitor func(Interface)) int {
 	return m.registry.visitAll(m.wrapVisitor(visitor))
 }
 
 func (m *manager) Route(request *Request) (*Response, error) {
-	if destination, err := request.ID(); err != nil {
+	return m.route(request, false)
+}
+
+func (m *manager) RouteContext(ctx context.Context, request *Request) (*Response, error) {
+	return m.route(request.WithContext(ctx), true)
+}
+
+// route is the common implementation of Route and RouteContext.  When busyOnCancel is set,
+// a request whose context ended before the device wrote it produces ErrorDeviceBusy.
+func (m *manager) route(request *Request, busyOnCancel bool) (*Response, error) {
+	if message, ok := request.Message.(*wrp.Message); ok && m.validateMessages {
+		if err := message.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	destination, err := request.ID()
+	if err != nil {
 		return nil, err
-	} else if d, ok := m.registry.get(destination); ok {
-		return d.Send(request)
+	}
+
+	d, ok := m.registry.get(destination)
+	if !ok {
+		return m.deliveryResponse(request, nil, ErrorDeviceNotFound)
+	}
+
+	response, written, err := d.send(request)
+	if busyOnCancel && !written && err != nil && err == request.Context().Err() {
+		err = ErrorDeviceBusy
+	}
+
+	if _, transactional := request.Transactional(); transactional {
+		return response, err
+	}
+
+	return m.deliveryResponse(request, d, err)
+}
+
+// deliveryResponse produces the delivery response, if any, for a request that did not start a
+// transaction.  The device will be nil if it could not be found.  The deliveryError is always
+// returned as is.
+//
+// The response is built from any wrp.Routable.  Routable types which carry no rdr field, such as
+// wrp.SimpleEvent, produce a response without one.
+func (m *manager) deliveryResponse(request *Request, d *device, deliveryError error) (*Response, error) {
+	if !m.deliveryResponses {
+		return nil, deliveryError
+	}
+
+	routable, ok := request.Message.(wrp.Routable)
+	if !ok {
+		return nil, deliveryError
+	}
+
+	rdr := DeliveryResponseDelivered
+	if deliveryError != nil {
+		rdr = DeliveryResponseFailed
+	}
+
+	response := &Response{
+		Format: wrp.Msgpack,
+	}
+
+	if d != nil {
+		response.Device = d
+	}
+
+	responseRoutable := routable.Response(routable.To(), rdr)
+	if err := m.encoderPools[wrp.Msgpack].EncodeBytes(&response.Contents, responseRoutable); err != nil {
+		m.errorLog.Log(logging.MessageKey(), "unable to encode delivery response", logging.ErrorKey(), err)
+		return nil, deliveryError
+	}
+
+	if message, ok := responseRoutable.(*wrp.Message); ok {
+		response.Message = message
 	} else {
-		return nil, ErrorDeviceNotFound
+		// other Routable types, e.g. wrp.SimpleRequestResponse, are normalized through their encoded form
+		response.Message = new(wrp.Message)
+		if err := wrp.NewDecoderBytes(response.Contents, wrp.Msgpack).Decode(response.Message); err != nil {
+			m.errorLog.Log(logging.MessageKey(), "unable to decode delivery response", logging.ErrorKey(), err)
+			return nil, deliveryError
+		}
+	}
+
+	return response, deliveryError
+}
+
+func (m *manager) SetFormat(id ID, f wrp.Format) error {
+	pool, ok := m.encoderPools[f]
+	if !ok {
+		return ErrorUnsupportedFormat
+	}
+
+	d, ok := m.registry.get(id)
+	if !ok {
+		return ErrorDeviceNotFound
+	}
+
+	d.setEncodeFormat(pool.Format())
+	return nil
+}
+
+func (m *manager) Replay(listener Listener, n int) {
+	events := m.replay.last(n)
+	for i := range events {
+		listener(&events[i])
+	}
+}
+
+// subscriber is a Listener registered through Subscribe.  Listeners are not comparable, so
+// each subscription is identified by its pointer.
+type subscriber struct {
+	listener Listener
+}
+
+func (m *manager) Subscribe(listener Listener, n int) func() {
+	s := &subscriber{listener}
+
+	m.subscribeLock.Lock()
+	// replaying while holding the lock holds up dispatch, so no later event can overtake a replayed one
+	m.Replay(listener, n)
+	subscribers := make([]*subscriber, len(m.subscribers), len(m.subscribers)+1)
+	copy(subscribers, m.subscribers)
+	m.subscribers = append(subscribers, s)
+	m.subscribeLock.Unlock()
+
+	return func() {
+		m.subscribeLock.Lock()
+		defer m.subscribeLock.Unlock()
+
+		for i, candidate := range m.subscribers {
+			if candidate == s {
+				subscribers := make([]*subscriber, 0, len(m.subscribers)-1)
+				subscribers = append(subscribers, m.subscribers[:i]...)
+				m.subscribers = append(subscribers, m.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (m *manager) SignalBackpressure(ids []ID, directive BackpressureDirective) int {
+	count := 0
+	for _, id := range ids {
+		if directive.Close {
+			if d, ok := m.registry.removeID(id); ok {
+				d.requestCloseCode(BackpressureCloseCode, directive.closeReason())
+				count++
+			}
+		} else if d, ok := m.registry.get(id); ok {
+			if d.trySend(&Request{Message: directive.newMessage(id)}) {
+				count++
+			} else {
+				d.debugLog.Log(logging.MessageKey(), "backpressure message dropped")
+			}
+		}
 	}
+
+	return count
+}
+
+func (m *manager) DeviceState(id ID) (DeviceStateSnapshot, error) {
+	if d, ok := m.registry.get(id); ok {
+		return newDeviceStateSnapshot(d), nil
+	}
+
+	return DeviceStateSnapshot{}, ErrorDeviceNotFound
+}
+
+func (m *manager) isShuttingDown() bool {
+	return atomic.LoadInt32(&m.shuttingDown) != 0
+}
+
+func (m *manager) Shutdown(ctx context.Context) (int, error) {
+	atomic.StoreInt32(&m.shuttingDown, 1)
+	m.registry.visitAll(func(d *device) {
+		d.requestClose()
+	})
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	for m.registry.len() > 0 {
+		select {
+		case <-ctx.Done():
+			forced := m.registry.removeIf(
+				func(ID) bool { return true },
+				func(d *device) {
+					d.errorLog.Log(logging.MessageKey(), "forcibly closing device during shutdown")
+					d.requestClose()
+				},
+			)
+
+			return forced, ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+
+	return 0, nil
 }
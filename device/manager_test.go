@@ -1,19 +1,27 @@
 package device
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/Comcast/webpa-common/logging"
 	"github.com/Comcast/webpa-common/wrp"
+	"github.com/Comcast/webpa-common/wrp/wrphttp"
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/websocket"
 	"github.com/justinas/alice"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -186,6 +194,43 @@ func testManagerConnectVisit(t *testing.T) {
 	assert.Equal(len(testDeviceIDs), deviceSet.len())
 }
 
+func testManagerDisconnectBatch(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		logger  = logging.NewTestLogger(nil, t)
+		manager = NewManager(&Options{Logger: logger}, new(mockConnectionFactory)).(*manager)
+		devices = make(map[ID]*device)
+	)
+
+	for _, id := range testDeviceIDs {
+		d := newDevice(id, 1, time.Now(), logger)
+		devices[id] = d
+		manager.registry.add(d)
+	}
+
+	assert.Equal(len(testDeviceIDs), manager.Len())
+	assert.Zero(manager.DisconnectBatch(nil))
+	assert.Zero(manager.DisconnectBatch([]ID{ID("nosuch")}))
+
+	// duplicates are only disconnected once, and unknown IDs are skipped
+	assert.Equal(
+		2,
+		manager.DisconnectBatch([]ID{testDeviceIDs[1], testDeviceIDs[3], ID("nosuch"), testDeviceIDs[1]}),
+	)
+
+	assert.Equal(len(testDeviceIDs)-2, manager.Len())
+	for id, d := range devices {
+		_, connected := manager.Get(id)
+		if id == testDeviceIDs[1] || id == testDeviceIDs[3] {
+			assert.True(d.Closed())
+			assert.False(connected)
+		} else {
+			assert.False(d.Closed())
+			assert.True(connected)
+		}
+	}
+}
+
 func testManagerPongCallbackFor(t *testing.T) {
 	assert := assert.New(t)
 	expectedDevice := newDevice(ID("ponged device"), 1, time.Now(), logging.NewTestLogger(nil, t))
@@ -438,23 +483,1657 @@ func testManagerPingPong(t *testing.T) {
 	pongWait.Wait()
 }
 
-func TestManager(t *testing.T) {
-	/*
-			t.Run("Connect", func(t *testing.T) {
-				t.Run("MissingDeviceContext", testManagerConnectMissingDeviceContext)
-				t.Run("ConnectionFactoryError", testManagerConnectConnectionFactoryError)
-				t.Run("Visit", testManagerConnectVisit)
-			})
+func testManagerReconnectCooldown(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnections = make(chan Interface, 10)
 
-			t.Run("Route", func(t *testing.T) {
-				t.Run("BadDestination", testManagerRouteBadDestination)
-				t.Run("DeviceNotFound", testManagerRouteDeviceNotFound)
-				t.Run("NonUniqueID", testManagerRouteNonUniqueID)
-			})
+		options = &Options{
+			Logger:            logging.NewTestLogger(nil, t),
+			ReconnectCooldown: 500 * time.Millisecond,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
 
-		t.Run("Disconnect", testManagerDisconnect)
-	*/
-	t.Run("DisconnectIf", testManagerDisconnectIf)
-	t.Run("PongCallbackFor", testManagerPongCallbackFor)
-	t.Run("PingPong", testManagerPingPong)
+		_, server, connectURL = startWebsocketServer(options)
+		dialer                = NewDialer(options, nil)
+		id                    = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	connectWait.Add(1)
+	connection, response, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	require.NotNil(connection)
+	assert.Equal(http.StatusSwitchingProtocols, response.StatusCode)
+	connectWait.Wait()
+
+	// the device hangs up, which starts the cooldown
+	assert.NoError(connection.Close())
+	select {
+	case d := <-disconnections:
+		assert.Equal(id, d.ID())
+	case <-time.After(5 * time.Second):
+		require.Fail("No disconnection occurred within the timeout")
+	}
+
+	connection, response, err = dialer.Dial(connectURL, id, nil)
+	assert.Nil(connection)
+	assert.Error(err)
+	require.NotNil(response)
+	assert.Equal(http.StatusTooManyRequests, response.StatusCode)
+	assert.Equal("1", response.Header.Get("Retry-After"))
+
+	// other devices are unaffected by the cooldown
+	connectWait.Add(1)
+	other, _, err := dialer.Dial(connectURL, testDeviceIDs[1], nil)
+	require.NoError(err)
+	defer other.Close()
+	connectWait.Wait()
+
+	time.Sleep(options.ReconnectCooldown)
+
+	connectWait.Add(1)
+	connection, response, err = dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	require.NotNil(connection)
+	defer connection.Close()
+	assert.Equal(http.StatusSwitchingProtocols, response.StatusCode)
+	connectWait.Wait()
+}
+
+func testManagerReconnect(t *testing.T, gracePeriod time.Duration, expectedType EventType) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		events  = make(chan Event, 10)
+
+		options = &Options{
+			Logger:               logging.NewTestLogger(nil, t),
+			ReconnectGracePeriod: gracePeriod,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect, Reconnect, Disconnect:
+						events <- *event
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	nextEvent := func() Event {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			require.FailNow("No event was dispatched within the timeout")
+			return Event{}
+		}
+	}
+
+	first, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer first.Close()
+
+	connected := nextEvent()
+	assert.Equal(Connect, connected.Type)
+	firstDevice := connected.Device
+
+	// the same ID dials while the first device is still connected, and the newest connection wins
+	second, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer second.Close()
+
+	var secondDevice Interface
+	for i := 0; i < 2; i++ {
+		event := nextEvent()
+		switch event.Type {
+		case Disconnect:
+			assert.True(firstDevice == event.Device)
+		default:
+			assert.Equal(expectedType, event.Type)
+			assert.False(firstDevice == event.Device)
+			secondDevice = event.Device
+		}
+	}
+
+	require.NotNil(secondDevice)
+	assert.True(firstDevice.Closed())
+	current, ok := manager.Get(id)
+	require.True(ok)
+	assert.True(secondDevice == current)
+	assert.Equal(1, manager.Len())
+
+	// the same ID dials again shortly after disconnecting
+	assert.True(manager.Disconnect(id))
+	disconnected := nextEvent()
+	assert.Equal(Disconnect, disconnected.Type)
+	assert.True(secondDevice == disconnected.Device)
+
+	third, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer third.Close()
+
+	reconnected := nextEvent()
+	assert.Equal(expectedType, reconnected.Type)
+	assert.Equal(id, reconnected.Device.ID())
+
+	manager.Disconnect(id)
+	assert.Equal(Disconnect, nextEvent().Type)
+}
+
+func testManagerReconnectCooldownServerDisconnect(t *testing.T, cooldownServerDisconnects bool) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connections    = make(chan Interface, 10)
+		disconnections = make(chan Interface, 10)
+
+		options = &Options{
+			Logger:                    logging.NewTestLogger(nil, t),
+			ReconnectCooldown:         10 * time.Second,
+			CooldownServerDisconnects: cooldownServerDisconnects,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connections <- event.Device
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer connection.Close()
+	<-connections
+
+	assert.True(manager.Disconnect(id))
+	select {
+	case <-disconnections:
+	case <-time.After(5 * time.Second):
+		require.Fail("No disconnection occurred within the timeout")
+	}
+
+	reconnection, response, err := dialer.Dial(connectURL, id, nil)
+	if cooldownServerDisconnects {
+		assert.Error(err)
+		require.NotNil(response)
+		assert.Equal(http.StatusTooManyRequests, response.StatusCode)
+	} else {
+		require.NoError(err)
+		reconnection.Close()
+		<-connections
+	}
+}
+
+func testManagerReconnectCooldownDuplicate(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connections    = make(chan Interface, 10)
+		disconnections = make(chan Interface, 10)
+
+		options = &Options{
+			Logger:                    logging.NewTestLogger(nil, t),
+			ReconnectCooldown:         10 * time.Second,
+			CooldownServerDisconnects: true,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connections <- event.Device
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer connection.Close()
+	replaced := <-connections
+
+	// the duplicate replaces the first connection, which the server then closes
+	duplicate, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	<-connections
+
+	select {
+	case d := <-disconnections:
+		assert.True(replaced == d)
+	case <-time.After(5 * time.Second):
+		require.Fail("The replaced connection was not disconnected")
+	}
+
+	// closing the replaced connection did not start a cooldown, so the live connection can be
+	// replaced yet again
+	redial, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	<-connections
+	duplicate.Close()
+	redial.Close()
+
+	for i := 0; i < 2; i++ {
+		<-disconnections
+	}
+
+	_, connected := manager.Get(id)
+	assert.False(connected)
+}
+
+func testManagerSetFormat(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		connectWait = new(sync.WaitGroup)
+
+		options = &Options{
+			Logger:    logging.NewTestLogger(nil, t),
+			AuthDelay: time.Hour,
+			Listeners: []Listener{
+				func(event *Event) {
+					if event.Type == Connect {
+						connectWait.Done()
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	assert.Equal(ErrorDeviceNotFound, manager.SetFormat(id, wrp.JSON))
+
+	connectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	assert.Equal(ErrorUnsupportedFormat, manager.SetFormat(id, wrp.Format(-1)))
+
+	for _, format := range []wrp.Format{wrp.JSON, wrp.Msgpack} {
+		require.NoError(manager.SetFormat(id, format))
+
+		expected := &wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "test",
+			Destination: string(id),
+			Payload:     []byte("format test"),
+		}
+
+		// Contents are only sent as is when they match the device's format
+		response, err := manager.Route(&Request{
+			Message:  expected,
+			Format:   wrp.Msgpack,
+			Contents: []byte("this is not the device's format"),
+		})
+
+		assert.Nil(response)
+		require.NoError(err)
+
+		var frame bytes.Buffer
+		frameRead, err := connection.Read(&frame)
+		require.NoError(err)
+		require.True(frameRead)
+
+		if format == wrp.Msgpack {
+			assert.Equal([]byte("this is not the device's format"), frame.Bytes())
+			continue
+		}
+
+		actual := new(wrp.Message)
+		require.NoError(wrp.NewDecoderBytes(frame.Bytes(), format).Decode(actual))
+		assert.Equal(expected, actual)
+	}
+}
+
+func testManagerAuditSink(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		auditSink   = newRecordingAuditSink()
+		connectWait = new(sync.WaitGroup)
+
+		options = &Options{
+			Logger:    logging.NewTestLogger(nil, t),
+			AuditSink: auditSink,
+			Listeners: []Listener{
+				func(event *Event) {
+					if event.Type == Connect {
+						connectWait.Done()
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		start                       = time.Now()
+	)
+
+	defer server.Close()
+
+	connectWait.Add(2)
+	deviceClosed, _, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	require.NoError(err)
+	serverClosed, _, err := dialer.Dial(connectURL, testDeviceIDs[1], nil)
+	require.NoError(err)
+	defer serverClosed.Close()
+	connectWait.Wait()
+
+	connected := auditSink.connectedRecords()
+	require.Len(connected, 2)
+	for i, record := range connected {
+		assert.Equal(testDeviceIDs[i], record.ID)
+		assert.NotEmpty(record.RemoteAddr)
+		assert.False(record.Timestamp.Before(start))
+		assert.Empty(record.Reason)
+	}
+
+	assert.True(manager.Disconnect(testDeviceIDs[1]))
+	select {
+	case record := <-auditSink.disconnected:
+		assert.Equal(testDeviceIDs[1], record.ID)
+		assert.Equal(connected[1].RemoteAddr, record.RemoteAddr)
+		assert.False(record.Timestamp.Before(connected[1].Timestamp))
+		assert.Equal(DisconnectReasonServer, record.Reason)
+	case <-time.After(5 * time.Second):
+		require.Fail("No disconnect audit record for the server disconnect")
+	}
+
+	assert.NoError(deviceClosed.Close())
+	select {
+	case record := <-auditSink.disconnected:
+		assert.Equal(testDeviceIDs[0], record.ID)
+		assert.Equal(connected[0].RemoteAddr, record.RemoteAddr)
+		assert.False(record.Timestamp.Before(connected[0].Timestamp))
+		assert.NotEmpty(record.Reason)
+		assert.NotEqual(DisconnectReasonServer, record.Reason)
+	case <-time.After(5 * time.Second):
+		require.Fail("No disconnect audit record for the device disconnect")
+	}
+
+	assert.Len(auditSink.connectedRecords(), 2)
+	select {
+	case record := <-auditSink.disconnected:
+		assert.Fail("Unexpected disconnect audit record", "%v", record)
+	default:
+	}
+}
+
+func testManagerReplay(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnectWait = new(sync.WaitGroup)
+
+		options = &Options{
+			Logger:          logging.NewTestLogger(nil, t),
+			AuthDelay:       time.Hour,
+			EventReplaySize: 2,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnectWait.Done()
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+	)
+
+	defer server.Close()
+
+	// wait for the devices to disconnect, so that nothing logs after this test completes
+	defer disconnectWait.Wait()
+
+	var replayed []ID
+	manager.Replay(func(e *Event) { replayed = append(replayed, e.Device.ID()) }, 10)
+	assert.Empty(replayed)
+
+	// connect devices one at a time, so that the order of events is known
+	for _, id := range testDeviceIDs[:3] {
+		connectWait.Add(1)
+		disconnectWait.Add(1)
+		connection, _, err := dialer.Dial(connectURL, id, nil)
+		require.NoError(err)
+		defer connection.Close()
+		connectWait.Wait()
+	}
+
+	var events []Event
+	manager.Replay(func(e *Event) { events = append(events, *e) }, 10)
+	require.Len(events, 2)
+	for i, id := range testDeviceIDs[1:3] {
+		assert.Equal(Connect, events[i].Type)
+		assert.Equal(id, events[i].Device.ID())
+	}
+
+	replayed = nil
+	manager.Replay(func(e *Event) { replayed = append(replayed, e.Device.ID()) }, 1)
+	assert.Equal([]ID{testDeviceIDs[2]}, replayed)
+}
+
+func testManagerSubscribe(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnectWait = new(sync.WaitGroup)
+
+		options = &Options{
+			Logger:          logging.NewTestLogger(nil, t),
+			AuthDelay:       time.Hour,
+			EventReplaySize: 10,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnectWait.Done()
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+
+		subscribed = make(chan ID, 10)
+		subscriber = func(e *Event) {
+			if e.Type == Connect {
+				subscribed <- e.Device.ID()
+			}
+		}
+	)
+
+	defer server.Close()
+
+	// wait for the devices to disconnect, so that nothing logs after this test completes
+	defer disconnectWait.Wait()
+
+	connectWait.Add(1)
+	disconnectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	unsubscribe := manager.Subscribe(subscriber, 10)
+	require.Len(subscribed, 1)
+	assert.Equal(testDeviceIDs[0], <-subscribed)
+
+	// events dispatched after Subscribe returns are delivered live, exactly once
+	for _, id := range testDeviceIDs[1:3] {
+		connectWait.Add(1)
+		disconnectWait.Add(1)
+		connection, _, err := dialer.Dial(connectURL, id, nil)
+		require.NoError(err)
+		defer connection.Close()
+		connectWait.Wait()
+	}
+
+	for _, id := range testDeviceIDs[1:3] {
+		select {
+		case actual := <-subscribed:
+			assert.Equal(id, actual)
+		case <-time.After(5 * time.Second):
+			require.Fail("The subscriber did not receive a live event")
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+
+	// subscribers are invoked in order, so once a later subscriber sees an event the first would have as well
+	after := make(chan ID, 10)
+	manager.Subscribe(func(e *Event) {
+		if e.Type == Connect {
+			after <- e.Device.ID()
+		}
+	}, 0)
+
+	connectWait.Add(1)
+	disconnectWait.Add(1)
+	connection, _, err = dialer.Dial(connectURL, testDeviceIDs[3], nil)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	select {
+	case actual := <-after:
+		assert.Equal(testDeviceIDs[3], actual)
+	case <-time.After(5 * time.Second):
+		require.Fail("The second subscriber did not receive a live event")
+	}
+
+	assert.Empty(subscribed)
+}
+
+func testManagerDeliveryResponses(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnectWait = new(sync.WaitGroup)
+
+		options = &Options{
+			Logger:            logging.NewTestLogger(nil, t),
+			AuthDelay:         time.Hour,
+			DeliveryResponses: true,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnectWait.Done()
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	connectWait.Add(1)
+	disconnectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	connectWait.Wait()
+
+	t.Run("Delivered", func(t *testing.T) {
+		response, err := manager.Route(&Request{
+			Message: &wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:sender.example.com",
+				Destination: string(id) + "/config",
+				Payload:     []byte("delivered"),
+			},
+		})
+
+		require.NoError(err)
+		require.NotNil(response)
+		assert.Equal(id, response.Device.ID())
+		require.NotNil(response.Message)
+		assert.Equal("dns:sender.example.com", response.Message.Destination)
+		assert.Equal(string(id)+"/config", response.Message.Source)
+		assert.Empty(response.Message.Payload)
+		require.NotNil(response.Message.RequestDeliveryResponse)
+		assert.Equal(DeliveryResponseDelivered, *response.Message.RequestDeliveryResponse)
+
+		decoded := new(wrp.Message)
+		assert.Equal(wrp.Msgpack, response.Format)
+		require.NoError(wrp.NewDecoderBytes(response.Contents, wrp.Msgpack).Decode(decoded))
+		assert.Equal(response.Message, decoded)
+
+		var frame bytes.Buffer
+		frameRead, err := connection.Read(&frame)
+		require.NoError(err)
+		assert.True(frameRead)
+	})
+
+	t.Run("DeviceNotFound", func(t *testing.T) {
+		response, err := manager.Route(&Request{
+			Message: &wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:sender.example.com",
+				Destination: string(testDeviceIDs[1]),
+			},
+		})
+
+		assert.Equal(ErrorDeviceNotFound, err)
+		require.NotNil(response)
+		assert.Nil(response.Device)
+		require.NotNil(response.Message)
+		assert.Equal("dns:sender.example.com", response.Message.Destination)
+		require.NotNil(response.Message.RequestDeliveryResponse)
+		assert.Equal(DeliveryResponseFailed, *response.Message.RequestDeliveryResponse)
+	})
+
+	t.Run("SimpleRequestResponse", func(t *testing.T) {
+		response, err := manager.Route(&Request{
+			Message: &wrp.SimpleRequestResponse{
+				Type:        wrp.SimpleRequestResponseMessageType,
+				Source:      "dns:sender.example.com",
+				Destination: string(testDeviceIDs[1]),
+				Payload:     []byte("not delivered"),
+			},
+		})
+
+		assert.Equal(ErrorDeviceNotFound, err)
+		require.NotNil(response)
+		require.NotNil(response.Message)
+		assert.Equal(wrp.SimpleRequestResponseMessageType, response.Message.Type)
+		assert.Equal("dns:sender.example.com", response.Message.Destination)
+		assert.Equal(string(testDeviceIDs[1]), response.Message.Source)
+		assert.Empty(response.Message.Payload)
+		require.NotNil(response.Message.RequestDeliveryResponse)
+		assert.Equal(DeliveryResponseFailed, *response.Message.RequestDeliveryResponse)
+
+		decoded := new(wrp.Message)
+		require.NoError(wrp.NewDecoderBytes(response.Contents, wrp.Msgpack).Decode(decoded))
+		assert.Equal(response.Message, decoded)
+	})
+
+	t.Run("SimpleEvent", func(t *testing.T) {
+		response, err := manager.Route(&Request{
+			Message: &wrp.SimpleEvent{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:sender.example.com",
+				Destination: string(id) + "/event",
+				Payload:     []byte("delivered"),
+			},
+		})
+
+		require.NoError(err)
+		require.NotNil(response)
+		assert.Equal(id, response.Device.ID())
+		require.NotNil(response.Message)
+		assert.Equal(wrp.SimpleEventMessageType, response.Message.Type)
+		assert.Equal("dns:sender.example.com", response.Message.Destination)
+		assert.Equal(string(id)+"/event", response.Message.Source)
+		assert.Nil(response.Message.RequestDeliveryResponse)
+
+		var frame bytes.Buffer
+		frameRead, err := connection.Read(&frame)
+		require.NoError(err)
+		assert.True(frameRead)
+	})
+
+	// the device's pumps log when it disconnects, so let that happen before this test ends
+	connection.Close()
+	disconnectWait.Wait()
+}
+
+func testManagerRouteWriteDeadline(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnections = make(chan Interface, 1)
+
+		options = &Options{
+			Logger:       logging.NewTestLogger(nil, t),
+			AuthDelay:    time.Hour,
+			WriteTimeout: time.Hour,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	// the device never reads, so a large enough write will block
+	connectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	response, err := manager.Route(
+		(&Request{
+			Message:  &wrp.Message{Type: wrp.SimpleEventMessageType, Destination: string(id)},
+			Format:   wrp.Msgpack,
+			Contents: make([]byte, 64*1024*1024),
+		}).WithContext(ctx),
+	)
+
+	// depending on timing, the caller sees either its own deadline or the write timeout
+	assert.Nil(response)
+	if err != context.DeadlineExceeded {
+		netError, ok := err.(net.Error)
+		if assert.True(ok, "expected a net.Error, got %T", err) {
+			assert.True(netError.Timeout())
+		}
+	}
+
+	// the blocked write is bounded by the caller's deadline, and the device is then disconnected
+	select {
+	case d := <-disconnections:
+		assert.Equal(id, d.ID())
+		assert.True(time.Since(start) < 5*time.Second)
+	case <-time.After(5 * time.Second):
+		assert.Fail("The device was not disconnected after its write timed out")
+	}
+}
+
+func testManagerSignalBackpressure(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnections = make(chan Interface, 1)
+
+		options = &Options{
+			Logger:    logging.NewTestLogger(nil, t),
+			AuthDelay: time.Hour,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+		directive                   = BackpressureDirective{Duration: 30 * time.Second, Source: "dns:server.example.com"}
+	)
+
+	defer server.Close()
+
+	assert.Zero(manager.SignalBackpressure([]ID{id}, directive))
+
+	connectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	// without Close, the device stays connected and receives a backpressure message
+	assert.Equal(1, manager.SignalBackpressure([]ID{id, testDeviceIDs[1]}, directive))
+
+	var frame bytes.Buffer
+	frameRead, err := connection.Read(&frame)
+	require.NoError(err)
+	require.True(frameRead)
+
+	message := new(wrp.Message)
+	require.NoError(wrp.NewDecoderBytes(frame.Bytes(), wrp.Msgpack).Decode(message))
+	assert.Equal(wrp.SimpleEventMessageType, message.Type)
+	assert.Equal("dns:server.example.com", message.Source)
+	assert.Equal(string(id), message.Destination)
+	assert.Equal("30s", message.Metadata[BackpressureDurationKey])
+	_, connected := manager.Get(id)
+	assert.True(connected)
+
+	// with Close, the device is disconnected with the backpressure close code
+	directive.Close = true
+	assert.Equal(1, manager.SignalBackpressure([]ID{id}, directive))
+
+	frame.Reset()
+	_, err = connection.Read(&frame)
+	closeError, ok := err.(*websocket.CloseError)
+	if assert.True(ok, "expected a *websocket.CloseError, got %T", err) {
+		assert.Equal(BackpressureCloseCode, closeError.Code)
+		assert.Equal("30s", closeError.Text)
+	}
+
+	select {
+	case d := <-disconnections:
+		assert.Equal(id, d.ID())
+	case <-time.After(5 * time.Second):
+		assert.Fail("The device was not disconnected")
+	}
+
+	assert.Zero(manager.SignalBackpressure([]ID{id}, directive))
+}
+
+func testManagerRouteValidateMessages(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		invalid = &wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "no scheme",
+			Destination: string(testDeviceIDs[0]),
+		}
+	)
+
+	// without validation, the invalid message is routed and the device simply isn't found
+	response, err := NewManager(nil, nil).Route(&Request{Message: invalid})
+	assert.Nil(response)
+	assert.Equal(ErrorDeviceNotFound, err)
+
+	response, err = NewManager(&Options{ValidateMessages: true}, nil).Route(&Request{Message: invalid})
+	assert.Nil(response)
+	if validationError, ok := err.(*wrp.ValidationError); assert.True(ok, "expected a *wrp.ValidationError, got %T", err) {
+		assert.Equal("source", validationError.Field)
+	}
+}
+
+func testManagerMaxDevices(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connections    = make(chan Interface, 10)
+		disconnections = make(chan Interface, 10)
+
+		options = &Options{
+			Logger:     logging.NewTestLogger(nil, t),
+			MaxDevices: 2,
+			AuthDelay:  time.Millisecond,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connections <- event.Device
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+
+		dialGate = new(sync.WaitGroup)
+		holdGate = new(sync.WaitGroup)
+		dialWait = new(sync.WaitGroup)
+		results  = make(chan error, 8)
+	)
+
+	defer server.Close()
+
+	// more devices than allowed connect concurrently.  Devices rejected before the websocket upgrade
+	// get a 503, while devices that lose the race afterward are closed with BackpressureCloseCode.
+	dialGate.Add(1)
+	holdGate.Add(1)
+	dialWait.Add(cap(results))
+	for i := 0; i < cap(results); i++ {
+		go func(id ID) {
+			defer dialWait.Done()
+			dialGate.Wait()
+
+			connection, response, err := dialer.Dial(connectURL, id, nil)
+			if err != nil {
+				if assert.NotNil(response) {
+					assert.Equal(http.StatusServiceUnavailable, response.StatusCode)
+				}
+
+				results <- ErrorDeviceLimitReached
+				return
+			}
+
+			defer connection.Close()
+
+			// accepted devices receive the authorization status
+			var frame bytes.Buffer
+			if _, err := connection.Read(&frame); err != nil {
+				closeError, ok := err.(*websocket.CloseError)
+				if assert.True(ok, "expected a *websocket.CloseError, got %T", err) {
+					assert.Equal(BackpressureCloseCode, closeError.Code)
+				}
+
+				results <- ErrorDeviceLimitReached
+				return
+			}
+
+			results <- nil
+
+			// hold the connection open until the assertions are done
+			holdGate.Wait()
+		}(IntToMAC(uint64(i)))
+	}
+
+	dialGate.Done()
+	accepted, rejected := 0, 0
+	for i := 0; i < cap(results); i++ {
+		select {
+		case err := <-results:
+			if err == nil {
+				accepted++
+			} else {
+				rejected++
+			}
+		case <-time.After(5 * time.Second):
+			require.Fail("Not all connection attempts completed within the timeout")
+		}
+	}
+
+	assert.Equal(options.MaxDevices, accepted)
+	assert.Equal(cap(results)-options.MaxDevices, rejected)
+	assert.Equal(options.MaxDevices, len(connections))
+	assert.Equal(options.MaxDevices, manager.VisitAll(func(Interface) {}))
+
+	// once full, new devices are rejected before the websocket upgrade
+	connection, response, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	assert.Nil(connection)
+	assert.Error(err)
+	require.NotNil(response)
+	assert.Equal(http.StatusServiceUnavailable, response.StatusCode)
+
+	holdGate.Done()
+	dialWait.Wait()
+
+	// wait for the accepted devices to disconnect, so that nothing is logged after this test completes
+	for i := 0; i < options.MaxDevices; i++ {
+		select {
+		case <-disconnections:
+		case <-time.After(5 * time.Second):
+			require.Fail("The accepted devices did not disconnect within the timeout")
+		}
+	}
+}
+
+func testManagerShutdown(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectWait    = new(sync.WaitGroup)
+		disconnections = make(chan Interface, 10)
+
+		options = &Options{
+			Logger:    logging.NewTestLogger(nil, t),
+			AuthDelay: time.Hour,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		connections                 []Connection
+	)
+
+	defer server.Close()
+
+	for _, id := range testDeviceIDs[:2] {
+		connectWait.Add(1)
+		connection, _, err := dialer.Dial(connectURL, id, nil)
+		require.NoError(err)
+		defer connection.Close()
+		connections = append(connections, connection)
+	}
+
+	connectWait.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	forced, err := manager.Shutdown(ctx)
+	assert.Zero(forced)
+	assert.NoError(err)
+	assert.Zero(manager.VisitAll(func(Interface) {}))
+
+	for _, connection := range connections {
+		var frame bytes.Buffer
+		_, err := connection.Read(&frame)
+		closeError, ok := err.(*websocket.CloseError)
+		if assert.True(ok, "expected a *websocket.CloseError, got %T", err) {
+			assert.Equal(websocket.CloseNormalClosure, closeError.Code)
+		}
+	}
+
+	for range connections {
+		select {
+		case <-disconnections:
+		case <-time.After(5 * time.Second):
+			require.Fail("Not all devices were disconnected within the timeout")
+		}
+	}
+
+	// no new connections are allowed once shutdown has started
+	connection, response, err := dialer.Dial(connectURL, testDeviceIDs[2], nil)
+	assert.Nil(connection)
+	assert.Error(err)
+	require.NotNil(response)
+	assert.Equal(http.StatusServiceUnavailable, response.StatusCode)
+}
+
+func testManagerShutdownForced(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		logger  = logging.NewTestLogger(nil, t)
+		manager = NewManager(&Options{Logger: logger}, new(mockConnectionFactory)).(*manager)
+
+		// this device has no pumps, so it never disconnects on its own
+		stuck = newDevice(testDeviceIDs[0], 1, time.Now(), logger)
+	)
+
+	manager.registry.add(stuck)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	forced, err := manager.Shutdown(ctx)
+	assert.Equal(1, forced)
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.True(stuck.Closed())
+	assert.Zero(manager.VisitAll(func(Interface) {}))
+}
+
+func testManagerRouteContextCancel(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		logger  = logging.NewTestLogger(nil, t)
+		manager = NewManager(&Options{Logger: logger}, new(mockConnectionFactory)).(*manager)
+
+		// this device has no pumps and no queue capacity, so it never accepts a request
+		stalled = newDevice(testDeviceIDs[0], 0, time.Now(), logger)
+		message = &wrp.Message{Type: wrp.SimpleEventMessageType, Destination: string(stalled.id)}
+	)
+
+	manager.registry.add(stalled)
+
+	for _, busyOnCancel := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error, 1)
+		go func() {
+			var err error
+			if busyOnCancel {
+				_, err = manager.RouteContext(ctx, &Request{Message: message})
+			} else {
+				_, err = manager.Route((&Request{Message: message}).WithContext(ctx))
+			}
+
+			result <- err
+		}()
+
+		// cancel while the route is blocked on the device
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-result:
+			if busyOnCancel {
+				assert.Equal(ErrorDeviceBusy, err)
+			} else {
+				assert.Equal(context.Canceled, err)
+			}
+		case <-time.After(5 * time.Second):
+			require.Fail("Route did not return after its context was cancelled")
+		}
+	}
+
+	// the other errors are unchanged
+	response, err := manager.RouteContext(
+		context.Background(),
+		&Request{Message: &wrp.Message{Type: wrp.SimpleEventMessageType, Destination: string(testDeviceIDs[1])}},
+	)
+
+	assert.Nil(response)
+	assert.Equal(ErrorDeviceNotFound, err)
+
+	stalled.requestClose()
+	response, err = manager.RouteContext(context.Background(), &Request{Message: message})
+	assert.Nil(response)
+	assert.Equal(ErrorDeviceClosed, err)
+}
+
+func testManagerVisitWhere(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		logger  = logging.NewTestLogger(nil, t)
+		manager = NewManager(&Options{Logger: logger}, new(mockConnectionFactory)).(*manager)
+		visited = make(map[ID]bool)
+	)
+
+	for i, id := range testDeviceIDs {
+		d := newDevice(id, 1, time.Now(), logger)
+		d.statistics.AddDuplications(i % 2)
+		manager.registry.add(d)
+	}
+
+	// select devices by a property other than their ID
+	assert.Equal(
+		len(testDeviceIDs)/2,
+		manager.VisitWhere(
+			func(d Interface) bool { return d.Statistics().Duplications() > 0 },
+			func(d Interface) { visited[d.ID()] = true },
+		),
+	)
+
+	assert.Equal(map[ID]bool{testDeviceIDs[1]: true, testDeviceIDs[3]: true}, visited)
+	assert.Equal(len(testDeviceIDs), manager.Len())
+	assert.Zero(manager.VisitWhere(
+		func(Interface) bool { return false },
+		func(Interface) { assert.Fail("The visitor should not have been called") },
+	))
+}
+
+func testManagerMetricsLabel(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		labels         = make(chan string, 1)
+		disconnections = make(chan Interface, 1)
+
+		options = &Options{
+			Logger:           logging.NewTestLogger(nil, t),
+			AuthDelay:        time.Hour,
+			MetricsIDBuckets: 4,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						// the label must already be available to listeners
+						labels <- event.Device.MetricsLabel()
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		_, server, connectURL = startWebsocketServer(options)
+		dialer                = NewDialer(options, nil)
+		id                    = testDeviceIDs[0]
+	)
+
+	defer server.Close()
+
+	connection, _, err := dialer.Dial(connectURL, id, nil)
+	require.NoError(err)
+
+	select {
+	case label := <-labels:
+		assert.Equal(NewIDLabeler(4)(id), label)
+		assert.NotEqual(string(id), label)
+	case <-time.After(5 * time.Second):
+		require.Fail("No connect event was dispatched")
+	}
+
+	connection.Close()
+	select {
+	case <-disconnections:
+	case <-time.After(5 * time.Second):
+		assert.Fail("The device was not disconnected")
+	}
+}
+
+func testManagerConnectMetadata(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connectEvents  = make(chan map[string]string, 1)
+		disconnections = make(chan Interface, 1)
+
+		options = &Options{
+			Logger:    logging.NewTestLogger(nil, t),
+			AuthDelay: time.Hour,
+			MetadataExtractor: func(request *http.Request) map[string]string {
+				return map[string]string{
+					"firmware": request.Header.Get("X-Firmware"),
+				}
+			},
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						// the metadata must already be available to listeners
+						connectEvents <- event.Device.Metadata()
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+	)
+
+	defer server.Close()
+
+	connection, _, err := dialer.Dial(connectURL, testDeviceIDs[0], http.Header{"X-Firmware": []string{"1.2.3"}})
+	require.NoError(err)
+	defer connection.Close()
+
+	select {
+	case metadata := <-connectEvents:
+		assert.Equal(map[string]string{"firmware": "1.2.3"}, metadata)
+	case <-time.After(5 * time.Second):
+		require.Fail("No connect event was dispatched")
+	}
+
+	d, ok := manager.Get(testDeviceIDs[0])
+	require.True(ok)
+
+	// callers receive a copy, so changes to it do not affect the device
+	metadata := d.Metadata()
+	metadata["firmware"] = "changed"
+	assert.Equal(map[string]string{"firmware": "1.2.3"}, d.Metadata())
+
+	manager.Disconnect(testDeviceIDs[0])
+	select {
+	case <-disconnections:
+	case <-time.After(5 * time.Second):
+		require.Fail("The device was not disconnected within the timeout")
+	}
+}
+
+type testContextKey struct{}
+
+func testManagerConnectContext(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		connections    = make(chan context.Context, 1)
+		disconnections = make(chan context.Context, 1)
+
+		options = &Options{
+			Logger: logging.NewTestLogger(nil, t),
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connections <- event.Device.Context()
+					case Disconnect:
+						// the context must already be cancelled when listeners learn of the disconnection
+						disconnections <- event.Device.Context()
+					}
+				},
+			},
+		}
+
+		manager = NewManager(options, nil)
+		server  = httptest.NewServer(
+			alice.New(
+				Timeout(options),
+				UseID.FromHeader,
+				func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+						next.ServeHTTP(response, request.WithContext(context.WithValue(request.Context(), testContextKey{}, "trace")))
+					})
+				},
+			).Then(&ConnectHandler{Logger: options.logger(), Connector: manager}),
+		)
+
+		dialer = NewDialer(options, nil)
+	)
+
+	defer server.Close()
+
+	connection, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), testDeviceIDs[0], nil)
+	require.NoError(err)
+	defer connection.Close()
+
+	var connectContext context.Context
+	select {
+	case connectContext = <-connections:
+	case <-time.After(5 * time.Second):
+		require.Fail("No connect event was dispatched")
+	}
+
+	require.NotNil(connectContext)
+	assert.Equal("trace", connectContext.Value(testContextKey{}))
+	assert.NoError(connectContext.Err())
+
+	manager.VisitAll(func(d Interface) {
+		assert.Equal("trace", d.Context().Value(testContextKey{}))
+		assert.NoError(d.Context().Err())
+	})
+
+	manager.Disconnect(testDeviceIDs[0])
+	select {
+	case disconnectContext := <-disconnections:
+		assert.True(connectContext == disconnectContext)
+		assert.Equal(context.Canceled, disconnectContext.Err())
+		assert.Equal("trace", disconnectContext.Value(testContextKey{}))
+	case <-time.After(5 * time.Second):
+		require.Fail("The device was not disconnected within the timeout")
+	}
+}
+
+func testManagerMessageEvents(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		events         = make(chan Event, 10)
+		disconnections = make(chan Interface, 1)
+
+		options = &Options{
+			Logger:    logging.NewTestLogger(nil, t),
+			AuthDelay: time.Hour,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect, MessageReceived, MessageSent:
+						// events are reused, so keep a copy
+						events <- *event
+					case Disconnect:
+						disconnections <- event.Device
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+
+		fromDevice = &wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      string(testDeviceIDs[0]),
+			Destination: "event:device-status",
+			Payload:     []byte("online"),
+		}
+
+		toDevice = &wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "dns:server.com",
+			Destination: string(testDeviceIDs[0]),
+		}
+
+		nextEvent = func() Event {
+			select {
+			case event := <-events:
+				return event
+			case <-time.After(5 * time.Second):
+				require.FailNow("No event was dispatched within the timeout")
+				return Event{}
+			}
+		}
+	)
+
+	defer server.Close()
+
+	connection, _, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	require.NoError(err)
+	defer connection.Close()
+
+	// the device sends a message right away, which must not be dispatched before the Connect event
+	var encoded []byte
+	require.NoError(wrp.NewEncoderBytes(&encoded, wrp.Msgpack).Encode(fromDevice))
+	_, err = connection.Write(encoded)
+	require.NoError(err)
+
+	event := nextEvent()
+	assert.Equal(Connect, event.Type)
+	assert.Equal(testDeviceIDs[0], event.Device.ID())
+
+	event = nextEvent()
+	require.Equal(MessageReceived, event.Type)
+	assert.Equal(testDeviceIDs[0], event.Device.ID())
+	assert.Equal(fromDevice, event.Message)
+	assert.Equal(wrp.Msgpack, event.Format)
+	assert.Equal(encoded, event.Contents)
+
+	response, err := manager.Route(&Request{Message: toDevice, Format: wrp.Msgpack})
+	assert.Nil(response)
+	require.NoError(err)
+
+	event = nextEvent()
+	require.Equal(MessageSent, event.Type)
+	assert.Equal(testDeviceIDs[0], event.Device.ID())
+	assert.Equal(toDevice, event.Message)
+
+	manager.Disconnect(testDeviceIDs[0])
+	select {
+	case <-disconnections:
+	case <-time.After(5 * time.Second):
+		require.Fail("The device was not disconnected within the timeout")
+	}
+}
+
+func testManagerIdleTimeout(t *testing.T) {
+	var (
+		assert         = assert.New(t)
+		require        = require.New(t)
+		auditSink      = newRecordingAuditSink()
+		connectWait    = new(sync.WaitGroup)
+		disconnections = make(chan Event, 1)
+
+		options = &Options{
+			Logger:     logging.NewTestLogger(nil, t),
+			AuthDelay:  time.Hour,
+			PingPeriod: time.Hour,
+			IdlePeriod: 250 * time.Millisecond,
+			AuditSink:  auditSink,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnections <- *event
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+	)
+
+	defer server.Close()
+
+	connectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, testDeviceIDs[0], nil)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	// the device never sends anything, so it must be reaped once the idle period elapses
+	select {
+	case event := <-disconnections:
+		assert.Equal(testDeviceIDs[0], event.Device.ID())
+		assert.Equal(DisconnectReasonIdle, event.Reason)
+	case <-time.After(5 * time.Second):
+		require.Fail("The idle device was not disconnected")
+	}
+
+	select {
+	case record := <-auditSink.disconnected:
+		assert.Equal(testDeviceIDs[0], record.ID)
+		assert.Equal(DisconnectReasonIdle, record.Reason)
+	case <-time.After(5 * time.Second):
+		require.Fail("No disconnection was audited")
+	}
+
+	_, ok := manager.Get(testDeviceIDs[0])
+	assert.False(ok)
+}
+
+func TestManager(t *testing.T) {
+	/*
+			t.Run("Connect", func(t *testing.T) {
+				t.Run("MissingDeviceContext", testManagerConnectMissingDeviceContext)
+				t.Run("ConnectionFactoryError", testManagerConnectConnectionFactoryError)
+				t.Run("Visit", testManagerConnectVisit)
+			})
+
+			t.Run("Route", func(t *testing.T) {
+				t.Run("BadDestination", testManagerRouteBadDestination)
+				t.Run("DeviceNotFound", testManagerRouteDeviceNotFound)
+				t.Run("NonUniqueID", testManagerRouteNonUniqueID)
+			})
+
+		t.Run("Disconnect", testManagerDisconnect)
+	*/
+	t.Run("DisconnectIf", testManagerDisconnectIf)
+	t.Run("DisconnectBatch", testManagerDisconnectBatch)
+	t.Run("PongCallbackFor", testManagerPongCallbackFor)
+	t.Run("PingPong", testManagerPingPong)
+	t.Run("VisitWhere", testManagerVisitWhere)
+	t.Run("SetFormat", testManagerSetFormat)
+	t.Run("AuditSink", testManagerAuditSink)
+	t.Run("Replay", testManagerReplay)
+	t.Run("Subscribe", testManagerSubscribe)
+	t.Run("DeliveryResponses", testManagerDeliveryResponses)
+	t.Run("RouteWriteDeadline", testManagerRouteWriteDeadline)
+	t.Run("SignalBackpressure", testManagerSignalBackpressure)
+	t.Run("RouteValidateMessages", testManagerRouteValidateMessages)
+	t.Run("RouteContextCancel", testManagerRouteContextCancel)
+	t.Run("MaxDevices", testManagerMaxDevices)
+	t.Run("Shutdown", testManagerShutdown)
+	t.Run("ShutdownForced", testManagerShutdownForced)
+	t.Run("ConnectMetadata", testManagerConnectMetadata)
+	t.Run("MetricsLabel", testManagerMetricsLabel)
+	t.Run("ConnectContext", testManagerConnectContext)
+	t.Run("MessageEvents", testManagerMessageEvents)
+	t.Run("IdleTimeout", testManagerIdleTimeout)
+
+	t.Run("ReconnectCooldown", func(t *testing.T) {
+		t.Run("DeviceDisconnect", testManagerReconnectCooldown)
+		t.Run("ServerDisconnect", func(t *testing.T) {
+			testManagerReconnectCooldownServerDisconnect(t, false)
+		})
+
+		t.Run("ServerDisconnectCooldown", func(t *testing.T) {
+			testManagerReconnectCooldownServerDisconnect(t, true)
+		})
+
+		t.Run("Duplicate", testManagerReconnectCooldownDuplicate)
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		t.Run("GracePeriod", func(t *testing.T) { testManagerReconnect(t, time.Minute, Reconnect) })
+		t.Run("NoGracePeriod", func(t *testing.T) { testManagerReconnect(t, 0, Connect) })
+	})
+}
+
+func benchmarkManagerBroadcast(b *testing.B, deviceCount int, preEncoded bool) {
+	var (
+		connectWait = new(sync.WaitGroup)
+		options     = &Options{
+			Logger:    log.NewNopLogger(),
+			AuthDelay: time.Hour,
+			Listeners: []Listener{
+				func(event *Event) {
+					if event.Type == Connect {
+						connectWait.Done()
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+
+		message = &wrp.SimpleEvent{
+			Source:      "dns:server.com",
+			Destination: "event:broadcast",
+			ContentType: "application/json",
+			Payload:     bytes.Repeat([]byte(`{"status": "online"}`), 50),
+		}
+
+		request = &Request{Message: message, Format: wrp.Msgpack}
+	)
+
+	defer server.Close()
+
+	if preEncoded {
+		// this is how an event decoded from an HTTP request is broadcast without re-encoding
+		entity := &wrphttp.Entity{Format: wrp.Msgpack, Contents: wrp.MustEncode(message, wrp.Msgpack)}
+		if err := wrp.NewDecoderBytes(entity.Contents, wrp.Msgpack).Decode(&entity.Message); err != nil {
+			b.Fatalf("Unable to decode entity: %s", err)
+		}
+
+		request = NewEntityRequest(entity)
+	}
+
+	connectWait.Add(deviceCount)
+	for i := 0; i < deviceCount; i++ {
+		connection, _, err := dialer.Dial(connectURL, IntToMAC(uint64(i)), nil)
+		if err != nil {
+			b.Fatalf("Unable to connect device %d: %s", i, err)
+		}
+
+		defer connection.Close()
+
+		// devices discard whatever they're sent, so that writes never block
+		go func() {
+			var frame bytes.Buffer
+			for {
+				frame.Reset()
+				if _, err := connection.Read(&frame); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	connectWait.Wait()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		manager.VisitAll(func(d Interface) {
+			if _, err := d.Send(request); err != nil {
+				b.Errorf("Unable to send to device %s: %s", d.ID(), err)
+			}
+		})
+	}
+}
+
+func BenchmarkManagerBroadcast(b *testing.B) {
+	for _, deviceCount := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("Devices=%d", deviceCount), func(b *testing.B) {
+			b.Run("Encoded", func(b *testing.B) { benchmarkManagerBroadcast(b, deviceCount, false) })
+			b.Run("PreEncoded", func(b *testing.B) { benchmarkManagerBroadcast(b, deviceCount, true) })
+		})
+	}
 }
@@ -1,7 +1,10 @@
 package device
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,12 +49,30 @@ func (m *mockDevice) Closed() bool {
 	return arguments.Bool(0)
 }
 
+func (m *mockDevice) Context() context.Context {
+	return m.Called().Get(0).(context.Context)
+}
+
+func (m *mockDevice) ConnectedAt() time.Time {
+	return m.Called().Get(0).(time.Time)
+}
+
+func (m *mockDevice) MetricsLabel() string {
+	return m.Called().String(0)
+}
+
 func (m *mockDevice) Statistics() Statistics {
 	arguments := m.Called()
 	first, _ := arguments.Get(0).(Statistics)
 	return first
 }
 
+func (m *mockDevice) Metadata() map[string]string {
+	arguments := m.Called()
+	first, _ := arguments.Get(0).(map[string]string)
+	return first
+}
+
 func (m *mockDevice) Send(request *Request) (*Response, error) {
 	arguments := m.Called(request)
 	first, _ := arguments.Get(0).(*Response)
@@ -139,6 +160,12 @@ func (m *mockRouter) Route(request *Request) (*Response, error) {
 	return first, arguments.Error(1)
 }
 
+func (m *mockRouter) RouteContext(ctx context.Context, request *Request) (*Response, error) {
+	arguments := m.Called(ctx, request)
+	first, _ := arguments.Get(0).(*Response)
+	return first, arguments.Error(1)
+}
+
 type mockConnector struct {
 	mock.Mock
 }
@@ -157,6 +184,10 @@ func (m *mockConnector) DisconnectIf(predicate func(ID) bool) int {
 	return m.Called(predicate).Int(0)
 }
 
+func (m *mockConnector) DisconnectBatch(ids []ID) int {
+	return m.Called(ids).Int(0)
+}
+
 type mockRegistry struct {
 	mock.Mock
 }
@@ -171,6 +202,43 @@ func (m *mockRegistry) VisitIf(predicate func(ID) bool, visitor func(Interface))
 	return m.Called(predicate, visitor).Int(0)
 }
 
+func (m *mockRegistry) VisitWhere(predicate func(Interface) bool, visitor func(Interface)) int {
+	return m.Called(predicate, visitor).Int(0)
+}
+
 func (m *mockRegistry) VisitAll(visitor func(Interface)) int {
 	return m.Called(visitor).Int(0)
 }
+
+func (m *mockRegistry) Len() int {
+	return m.Called().Int(0)
+}
+
+// recordingAuditSink is an AuditSink that captures the records it receives
+type recordingAuditSink struct {
+	lock         sync.Mutex
+	connected    []AuditRecord
+	disconnected chan AuditRecord
+}
+
+func newRecordingAuditSink() *recordingAuditSink {
+	return &recordingAuditSink{
+		disconnected: make(chan AuditRecord, 10),
+	}
+}
+
+func (r *recordingAuditSink) Connected(record AuditRecord) {
+	r.lock.Lock()
+	r.connected = append(r.connected, record)
+	r.lock.Unlock()
+}
+
+func (r *recordingAuditSink) Disconnected(record AuditRecord) {
+	r.disconnected <- record
+}
+
+func (r *recordingAuditSink) connectedRecords() []AuditRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]AuditRecord(nil), r.connected...)
+}
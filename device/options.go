@@ -1,6 +1,8 @@
 package device
 
 import (
+	"compress/flate"
+	"net/http"
 	"time"
 
 	"github.com/Comcast/webpa-common/logging"
@@ -28,6 +30,11 @@ const (
 	DefaultReadBufferSize         = 4096
 	DefaultWriteBufferSize        = 4096
 	DefaultDeviceMessageQueueSize = 100
+	DefaultMetricsIDBuckets       = 256
+
+	// DefaultCompressionLevel is the flate compression level used for websocket messages when
+	// compression is enabled.  This is the same level the gorilla websocket library uses by default.
+	DefaultCompressionLevel = flate.BestSpeed
 )
 
 // Options represent the available configuration options for components
@@ -60,6 +67,15 @@ type Options struct {
 	// Subprotocols is the optional slice of websocket subprotocols to use.
 	Subprotocols []string
 
+	// EnableCompression controls whether the permessage-deflate websocket extension is offered
+	// by Dialers and accepted by ConnectionFactories.  Compression is only used when both sides
+	// of a connection support it, so peers without compression support connect normally.
+	EnableCompression bool
+
+	// CompressionLevel is the flate compression level, from flate.HuffmanOnly through flate.BestCompression,
+	// used for messages written to compressed connections.  If not supplied, DefaultCompressionLevel is used.
+	CompressionLevel int
+
 	// DeviceMessageQueueSize is the capacity of the channel which stores messages waiting
 	// to be transmitted to a device.  If not supplied, DefaultDeviceMessageQueueSize is used.
 	DeviceMessageQueueSize int
@@ -71,7 +87,9 @@ type Options struct {
 	AuthDelay time.Duration
 
 	// IdlePeriod is the length of time a device connection is allowed to be idle,
-	// with no traffic coming from the device.  If not supplied, DefaultIdlePeriod is used.
+	// with no traffic coming from the device.  Each inbound frame and each pong restarts
+	// the idle period.  A device that stays silent for longer is disconnected, and its
+	// disconnection is audited with DisconnectReasonIdle.  If not supplied, DefaultIdlePeriod is used.
 	IdlePeriod time.Duration
 
 	// RequestTimeout is the timeout for all inbound HTTP requests
@@ -81,9 +99,61 @@ type Options struct {
 	// DefaultWriteTimeout is used.
 	WriteTimeout time.Duration
 
+	// MaxDevices is the maximum number of devices that may be connected at once.  Once this limit is
+	// reached, connection attempts from new devices are rejected with a 503 status.  A device reconnecting
+	// under an ID that is already connected replaces the existing device and so is never rejected.
+	// If not supplied, the number of devices is unlimited.
+	MaxDevices int
+
+	// ReconnectCooldown is the length of time after a disconnection during which connection
+	// attempts from the same device ID are rejected with a 429 status.  If not supplied,
+	// reconnects are never throttled.
+	ReconnectCooldown time.Duration
+
+	// CooldownServerDisconnects controls whether ReconnectCooldown also applies to disconnections
+	// initiated by the server, e.g. via Disconnect or DisconnectIf.  By default, only disconnections
+	// caused by the device itself or by connection errors start a cooldown.
+	CooldownServerDisconnects bool
+
+	// ReconnectGracePeriod is the length of time after a disconnection during which a connection from the
+	// same device ID is reported to listeners as a Reconnect rather than a Connect.  When this is set, a device
+	// connecting while another device with the same ID is still connected is also reported as a Reconnect.
+	// In either case, the newest connection always replaces the existing device.  If not supplied, Reconnect
+	// events are never dispatched.
+	ReconnectGracePeriod time.Duration
+
+	// DeliveryResponses controls whether Manager.Route answers messages that do not start a transaction
+	// with a delivery response carrying an rdr (RequestDeliveryResponse) value.  By default, Route
+	// returns no response for such messages.
+	DeliveryResponses bool
+
+	// ValidateMessages controls whether Manager.Route validates each wrp.Message before routing it.
+	// Messages that fail validation are rejected with a *wrp.ValidationError.  By default, messages
+	// are routed without validation.
+	ValidateMessages bool
+
+	// MetricsIDBuckets is the number of buckets that device IDs are hashed into when used
+	// as metrics labels, as returned by Interface.MetricsLabel.  If not supplied,
+	// DefaultMetricsIDBuckets is used.
+	MetricsIDBuckets int
+
+	// EventReplaySize is the number of recent events retained for Manager.Replay.  If not
+	// supplied, no events are retained.
+	EventReplaySize int
+
 	// Listeners contains the event sinks for managers created using these options
 	Listeners []Listener
 
+	// MetadataExtractor is the optional function used to capture metadata from each device's connection
+	// request, such as the negotiated subprotocol or partner IDs from the URL.  The returned map is copied,
+	// and the copy is available from the device's Metadata method before any Connect listener is invoked.
+	// If not supplied, devices have no metadata.
+	MetadataExtractor func(*http.Request) map[string]string
+
+	// AuditSink is the optional destination for connect and disconnect audit records.
+	// If not supplied, no audit records are produced.
+	AuditSink AuditSink
+
 	// Logger is the output sink for log messages.  If not supplied, log output
 	// is sent to a NOP logger.
 	Logger log.Logger
@@ -169,6 +239,34 @@ func (o *Options) writeTimeout() time.Duration {
 	return DefaultWriteTimeout
 }
 
+func (o *Options) maxDevices() int {
+	if o != nil && o.MaxDevices > 0 {
+		return o.MaxDevices
+	}
+
+	return 0
+}
+
+func (o *Options) reconnectCooldown() time.Duration {
+	if o != nil && o.ReconnectCooldown > 0 {
+		return o.ReconnectCooldown
+	}
+
+	return 0
+}
+
+func (o *Options) reconnectGracePeriod() time.Duration {
+	if o != nil && o.ReconnectGracePeriod > 0 {
+		return o.ReconnectGracePeriod
+	}
+
+	return 0
+}
+
+func (o *Options) cooldownServerDisconnects() bool {
+	return o != nil && o.CooldownServerDisconnects
+}
+
 func (o *Options) readBufferSize() int {
 	if o != nil && o.ReadBufferSize > 0 {
 		return o.ReadBufferSize
@@ -194,6 +292,18 @@ func (o *Options) subprotocols() (subprotocols []string) {
 	return
 }
 
+func (o *Options) enableCompression() bool {
+	return o != nil && o.EnableCompression
+}
+
+func (o *Options) compressionLevel() int {
+	if o != nil && o.CompressionLevel != 0 {
+		return o.CompressionLevel
+	}
+
+	return DefaultCompressionLevel
+}
+
 func (o *Options) logger() log.Logger {
 	if o != nil && o.Logger != nil {
 		return o.Logger
@@ -209,3 +319,43 @@ func (o *Options) listeners() []Listener {
 
 	return nil
 }
+
+func (o *Options) metadataExtractor() func(*http.Request) map[string]string {
+	if o != nil {
+		return o.MetadataExtractor
+	}
+
+	return nil
+}
+
+func (o *Options) deliveryResponses() bool {
+	return o != nil && o.DeliveryResponses
+}
+
+func (o *Options) validateMessages() bool {
+	return o != nil && o.ValidateMessages
+}
+
+func (o *Options) metricsIDBuckets() int {
+	if o != nil && o.MetricsIDBuckets > 0 {
+		return o.MetricsIDBuckets
+	}
+
+	return DefaultMetricsIDBuckets
+}
+
+func (o *Options) eventReplaySize() int {
+	if o != nil && o.EventReplaySize > 0 {
+		return o.EventReplaySize
+	}
+
+	return 0
+}
+
+func (o *Options) auditSink() AuditSink {
+	if o != nil && o.AuditSink != nil {
+		return o.AuditSink
+	}
+
+	return nopAuditSink{}
+}
@@ -1,6 +1,8 @@
 package device
 
 import (
+	"compress/flate"
+	"net/http"
 	"testing"
 	"time"
 
@@ -23,11 +25,23 @@ func TestOptionsDefault(t *testing.T) {
 		assert.Equal(DefaultPingPeriod, o.pingPeriod())
 		assert.Equal(DefaultAuthDelay, o.authDelay())
 		assert.Equal(DefaultWriteTimeout, o.writeTimeout())
+		assert.Zero(o.maxDevices())
+		assert.Zero(o.reconnectCooldown())
+		assert.Zero(o.reconnectGracePeriod())
+		assert.False(o.cooldownServerDisconnects())
 		assert.Equal(DefaultReadBufferSize, o.readBufferSize())
 		assert.Equal(DefaultWriteBufferSize, o.writeBufferSize())
 		assert.Empty(o.subprotocols())
+		assert.False(o.enableCompression())
+		assert.Equal(DefaultCompressionLevel, o.compressionLevel())
 		assert.NotNil(o.logger())
 		assert.Empty(o.listeners())
+		assert.False(o.deliveryResponses())
+		assert.False(o.validateMessages())
+		assert.Equal(DefaultMetricsIDBuckets, o.metricsIDBuckets())
+		assert.Zero(o.eventReplaySize())
+		assert.Nil(o.metadataExtractor())
+		assert.Equal(nopAuditSink{}, o.auditSink())
 	}
 }
 
@@ -44,13 +58,24 @@ func TestOptions(t *testing.T) {
 			ReadBufferSize:         DefaultReadBufferSize + 48729,
 			WriteBufferSize:        DefaultWriteBufferSize + 926,
 			Subprotocols:           []string{"foobar"},
+			EnableCompression:      true,
+			CompressionLevel:       flate.BestCompression,
 			DeviceMessageQueueSize: DefaultDeviceMessageQueueSize + 287342,
 			IdlePeriod:             DefaultIdlePeriod + 3472*time.Minute,
 			PingPeriod:             DefaultPingPeriod + 384*time.Millisecond,
 			AuthDelay:              DefaultAuthDelay + 88*time.Millisecond,
 			WriteTimeout:           DefaultWriteTimeout + 327193*time.Second,
+			MaxDevices:             5000,
+			ReconnectCooldown:      15 * time.Second,
+			ReconnectGracePeriod:   30 * time.Second,
 			Logger:                 expectedLogger,
 			Listeners:              []Listener{func(*Event) {}},
+			DeliveryResponses:      true,
+			ValidateMessages:       true,
+			MetricsIDBuckets:       16,
+			EventReplaySize:        50,
+			MetadataExtractor:      func(*http.Request) map[string]string { return nil },
+			AuditSink:              new(recordingAuditSink),
 		}
 	)
 
@@ -63,9 +88,21 @@ func TestOptions(t *testing.T) {
 	assert.Equal(o.PingPeriod, o.pingPeriod())
 	assert.Equal(o.AuthDelay, o.authDelay())
 	assert.Equal(o.WriteTimeout, o.writeTimeout())
+	assert.Equal(o.MaxDevices, o.maxDevices())
+	assert.Equal(o.ReconnectCooldown, o.reconnectCooldown())
+	assert.Equal(o.ReconnectGracePeriod, o.reconnectGracePeriod())
+	assert.False(o.cooldownServerDisconnects())
 	assert.Equal(o.ReadBufferSize, o.readBufferSize())
 	assert.Equal(o.WriteBufferSize, o.writeBufferSize())
 	assert.Equal(o.Subprotocols, o.subprotocols())
+	assert.True(o.enableCompression())
+	assert.Equal(flate.BestCompression, o.compressionLevel())
 	assert.Equal(expectedLogger, o.logger())
 	assert.Equal(o.Listeners, o.listeners())
+	assert.True(o.deliveryResponses())
+	assert.True(o.validateMessages())
+	assert.Equal(o.MetricsIDBuckets, o.metricsIDBuckets())
+	assert.Equal(o.EventReplaySize, o.eventReplaySize())
+	assert.NotNil(o.metadataExtractor())
+	assert.Equal(o.AuditSink, o.auditSink())
 }
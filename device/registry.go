@@ -5,29 +5,72 @@ import (
 )
 
 type registry struct {
-	lock    sync.RWMutex
-	devices map[ID]*device
+	lock       sync.RWMutex
+	devices    map[ID]*device
+	maxDevices int
 }
 
-func newRegistry(initialCapacity uint32) *registry {
+// newRegistry creates a registry which holds at most maxDevices devices.  If maxDevices
+// is nonpositive, the registry is unbounded.
+func newRegistry(initialCapacity uint32, maxDevices int) *registry {
 	return &registry{
-		devices: make(map[ID]*device, initialCapacity),
+		devices:    make(map[ID]*device, initialCapacity),
+		maxDevices: maxDevices,
 	}
 }
 
-func (r *registry) add(d *device) *device {
+// add inserts the given device, returning any existing device with the same ID that it replaced.
+// Replacing a device never counts against the maximum.  If adding the device would exceed the maximum
+// number of devices, the device is not added and ErrorDeviceLimitReached is returned.
+func (r *registry) add(d *device) (*device, error) {
+	defer r.lock.Unlock()
 	r.lock.Lock()
-	existing := r.devices[d.id]
+
+	existing, ok := r.devices[d.id]
+	if !ok && r.maxDevices > 0 && len(r.devices) >= r.maxDevices {
+		return nil, ErrorDeviceLimitReached
+	}
+
 	r.devices[d.id] = d
-	r.lock.Unlock()
+	return existing, nil
+}
+
+// len returns the number of devices in this registry
+func (r *registry) len() int {
+	r.lock.RLock()
+	count := len(r.devices)
+	r.lock.RUnlock()
+
+	return count
+}
+
+// full tests whether this registry has reached its maximum number of devices
+func (r *registry) full() bool {
+	if r.maxDevices < 1 {
+		return false
+	}
+
+	r.lock.RLock()
+	full := len(r.devices) >= r.maxDevices
+	r.lock.RUnlock()
 
-	return existing
+	return full
 }
 
-func (r *registry) remove(d *device) {
+// remove removes the given device.  If the device has already been replaced by another device with
+// the same ID, the replacement is left in place and this method returns true.
+func (r *registry) remove(d *device) (superseded bool) {
 	r.lock.Lock()
-	delete(r.devices, d.id)
+	if existing, ok := r.devices[d.id]; ok {
+		if existing == d {
+			delete(r.devices, d.id)
+		} else {
+			superseded = true
+		}
+	}
+
 	r.lock.Unlock()
+	return
 }
 
 func (r *registry) removeID(id ID) (*device, bool) {
@@ -39,6 +82,25 @@ func (r *registry) removeID(id ID) (*device, bool) {
 	return existing, ok
 }
 
+// removeIDs removes each of the given IDs, invoking the visitor for each device that was actually
+// removed.  Duplicate and unknown IDs are skipped.  Each ID is looked up directly, so this method
+// does not iterate over all devices.
+func (r *registry) removeIDs(ids []ID, visitor func(*device)) int {
+	defer r.lock.Unlock()
+	r.lock.Lock()
+
+	count := 0
+	for _, id := range ids {
+		if existing, ok := r.devices[id]; ok {
+			count++
+			delete(r.devices, id)
+			visitor(existing)
+		}
+	}
+
+	return count
+}
+
 func (r *registry) removeIf(filter func(ID) bool, visitor func(*device)) int {
 	defer r.lock.Unlock()
 	r.lock.Lock()
@@ -81,6 +143,21 @@ func (r *registry) visitIf(filter func(ID) bool, visitor func(*device)) int {
 	return count
 }
 
+func (r *registry) visitWhere(predicate func(*device) bool, visitor func(*device)) int {
+	defer r.lock.RUnlock()
+	r.lock.RLock()
+
+	count := 0
+	for _, candidate := range r.devices {
+		if predicate(candidate) {
+			count++
+			visitor(candidate)
+		}
+	}
+
+	return count
+}
+
 func (r *registry) get(id ID) (*device, bool) {
 	r.lock.RLock()
 	existing, ok := r.devices[id]
@@ -33,13 +33,19 @@ func testRegistryConcurrentAddAndVisit(t *testing.T, r *registry) {
 				second = &device{id: id}
 			)
 
-			assert.Nil(r.add(first))
+			existing, err := r.add(first)
+			assert.Nil(existing)
+			assert.NoError(err)
+
 			existing, ok := r.get(id)
 			assert.True(first == existing)
 			assert.Equal(id, existing.id)
 			assert.True(ok)
 
-			assert.True(first == r.add(second))
+			existing, err = r.add(second)
+			assert.True(first == existing)
+			assert.NoError(err)
+
 			existing, ok = r.get(id)
 			assert.True(second == existing)
 			assert.Equal(id, existing.id)
@@ -117,14 +123,19 @@ func testRegistryConcurrentAddAndRemove(t *testing.T, r *registry) {
 			addAndRemoveGate.Wait()
 
 			d := &device{id: id}
-			assert.Nil(r.add(d))
+			added, err := r.add(d)
+			assert.Nil(added)
+			assert.NoError(err)
 			r.remove(d)
 
 			existing, ok := r.get(id)
 			assert.Nil(existing)
 			assert.False(ok)
 
-			assert.Nil(r.add(d))
+			added, err = r.add(d)
+			assert.Nil(added)
+			assert.NoError(err)
+
 			removed, ok := r.removeID(id)
 			assert.True(d == removed)
 			assert.True(ok)
@@ -133,7 +144,9 @@ func testRegistryConcurrentAddAndRemove(t *testing.T, r *registry) {
 			assert.Nil(existing)
 			assert.False(ok)
 
-			assert.Nil(r.add(d))
+			added, err = r.add(d)
+			assert.Nil(added)
+			assert.NoError(err)
 
 			assert.Equal(
 				1,
@@ -148,16 +161,141 @@ func testRegistryConcurrentAddAndRemove(t *testing.T, r *registry) {
 	}
 }
 
+func testRegistryMaxDevices(t *testing.T) {
+	var (
+		assert     = assert.New(t)
+		maxDevices = 10
+		r          = newRegistry(0, maxDevices)
+
+		addGate = new(sync.WaitGroup)
+		addWait = new(sync.WaitGroup)
+		results = make(chan error, 5*maxDevices)
+	)
+
+	assert.False(r.full())
+
+	// many more devices than allowed try to connect concurrently
+	addGate.Add(1)
+	addWait.Add(cap(results))
+	for i := 0; i < cap(results); i++ {
+		go func(id ID) {
+			defer addWait.Done()
+			addGate.Wait()
+
+			_, err := r.add(&device{id: id})
+			results <- err
+		}(IntToMAC(uint64(i)))
+	}
+
+	addGate.Done()
+	addWait.Wait()
+	close(results)
+
+	added := 0
+	for err := range results {
+		if err == nil {
+			added++
+		} else {
+			assert.Equal(ErrorDeviceLimitReached, err)
+		}
+	}
+
+	assert.Equal(maxDevices, added)
+	assert.Equal(maxDevices, r.visitAll(func(*device) {}))
+	assert.True(r.full())
+
+	// replacing a device with the same ID is always allowed
+	var replaced ID
+	r.visitAll(func(d *device) { replaced = d.id })
+	replacement := &device{id: replaced}
+	existing, err := r.add(replacement)
+	assert.NotNil(existing)
+	assert.NoError(err)
+
+	// removing a device makes room for exactly one more
+	r.remove(replacement)
+	assert.False(r.full())
+
+	existing, err = r.add(&device{id: ID("new")})
+	assert.Nil(existing)
+	assert.NoError(err)
+
+	existing, err = r.add(&device{id: ID("another")})
+	assert.Nil(existing)
+	assert.Equal(ErrorDeviceLimitReached, err)
+}
+
+func testRegistryRemoveIDs(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		r       = newRegistry(0, 0)
+		removed []ID
+	)
+
+	for _, id := range testDeviceIDs {
+		r.add(&device{id: id})
+	}
+
+	assert.Zero(r.removeIDs(nil, func(*device) { assert.Fail("The visitor should not have been called") }))
+	assert.Equal(
+		2,
+		r.removeIDs(
+			[]ID{testDeviceIDs[0], ID("nosuch"), testDeviceIDs[2], testDeviceIDs[0]},
+			func(d *device) { removed = append(removed, d.id) },
+		),
+	)
+
+	assert.Equal([]ID{testDeviceIDs[0], testDeviceIDs[2]}, removed)
+	assert.Equal(len(testDeviceIDs)-2, r.len())
+
+	_, ok := r.get(testDeviceIDs[0])
+	assert.False(ok)
+	_, ok = r.get(testDeviceIDs[1])
+	assert.True(ok)
+}
+
+func testRegistryRemoveReplaced(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		r           = newRegistry(0, 0)
+		replaced    = &device{id: ID("test")}
+		replacement = &device{id: ID("test")}
+	)
+
+	r.add(replaced)
+	existing, err := r.add(replacement)
+	assert.True(replaced == existing)
+	assert.NoError(err)
+
+	// removing a replaced device must not remove its replacement
+	assert.True(r.remove(replaced))
+	actual, ok := r.get(ID("test"))
+	assert.True(replacement == actual)
+	assert.True(ok)
+
+	assert.False(r.remove(replacement))
+	actual, ok = r.get(ID("test"))
+	assert.Nil(actual)
+	assert.False(ok)
+
+	// a device already removed by ID is not superseded
+	assert.False(r.remove(replaced))
+}
+
 func TestRegistry(t *testing.T) {
 	t.Run("ConcurrentAddAndVisit", func(t *testing.T) {
-		testRegistryConcurrentAddAndVisit(t, newRegistry(0))
-		testRegistryConcurrentAddAndVisit(t, newRegistry(1))
-		testRegistryConcurrentAddAndVisit(t, newRegistry(100))
+		testRegistryConcurrentAddAndVisit(t, newRegistry(0, 0))
+		testRegistryConcurrentAddAndVisit(t, newRegistry(1, 0))
+		testRegistryConcurrentAddAndVisit(t, newRegistry(100, 0))
 	})
 
 	t.Run("ConcurrentAddAndRemove", func(t *testing.T) {
-		testRegistryConcurrentAddAndRemove(t, newRegistry(0))
-		testRegistryConcurrentAddAndRemove(t, newRegistry(1))
-		testRegistryConcurrentAddAndRemove(t, newRegistry(100))
+		testRegistryConcurrentAddAndRemove(t, newRegistry(0, 0))
+		testRegistryConcurrentAddAndRemove(t, newRegistry(1, 0))
+		testRegistryConcurrentAddAndRemove(t, newRegistry(100, 0))
 	})
+
+	t.Run("MaxDevices", testRegistryMaxDevices)
+	t.Run("RemoveIDs", testRegistryRemoveIDs)
+	t.Run("RemoveReplaced", testRegistryRemoveReplaced)
 }
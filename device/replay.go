@@ -0,0 +1,73 @@
+package device
+
+import (
+	"sync"
+)
+
+// eventBuffer is a bounded ring of the most recent events dispatched by a manager.
+// Since the manager reuses Event instances, the buffer stores shallow copies.
+type eventBuffer struct {
+	lock   sync.Mutex
+	events []Event
+	next   int
+	full   bool
+}
+
+// newEventBuffer creates an eventBuffer that holds at most size events.  If size
+// is nonpositive, this function returns nil, which is a valid buffer that retains nothing.
+func newEventBuffer(size int) *eventBuffer {
+	if size < 1 {
+		return nil
+	}
+
+	return &eventBuffer{
+		events: make([]Event, size),
+	}
+}
+
+// add records a copy of the given event, overwriting the oldest event if the buffer is full
+func (eb *eventBuffer) add(e *Event) {
+	if eb == nil {
+		return
+	}
+
+	eb.lock.Lock()
+	eb.events[eb.next] = *e
+	eb.next++
+	if eb.next == len(eb.events) {
+		eb.next = 0
+		eb.full = true
+	}
+
+	eb.lock.Unlock()
+}
+
+// last returns copies of at most n of the most recent events, oldest first
+func (eb *eventBuffer) last(n int) []Event {
+	if eb == nil || n < 1 {
+		return nil
+	}
+
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	size := eb.next
+	if eb.full {
+		size = len(eb.events)
+	}
+
+	if n > size {
+		n = size
+	}
+
+	result := make([]Event, n)
+	for i, position := 0, eb.next-n; i < n; i, position = i+1, position+1 {
+		if position < 0 {
+			result[i] = eb.events[position+len(eb.events)]
+		} else {
+			result[i] = eb.events[position]
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package device
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBufferDisabled(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, size := range []int{-1, 0} {
+		eb := newEventBuffer(size)
+		assert.Nil(eb)
+
+		eb.add(&Event{Type: Connect})
+		assert.Empty(eb.last(10))
+	}
+}
+
+func TestEventBuffer(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		eb     = newEventBuffer(3)
+		event  Event
+	)
+
+	assert.Empty(eb.last(3))
+
+	for i := 0; i < 5; i++ {
+		// the same instance is reused, just as a manager does
+		event.Clear()
+		event.Data = fmt.Sprintf("event-%d", i)
+		eb.add(&event)
+
+		var (
+			expected []string
+			actual   []string
+			first    = i - 2
+		)
+
+		if first < 0 {
+			first = 0
+		}
+
+		for j := first; j <= i; j++ {
+			expected = append(expected, fmt.Sprintf("event-%d", j))
+		}
+
+		for _, e := range eb.last(10) {
+			actual = append(actual, e.Data)
+		}
+
+		assert.Equal(expected, actual)
+	}
+
+	last := eb.last(2)
+	if assert.Len(last, 2) {
+		assert.Equal("event-3", last[0].Data)
+		assert.Equal("event-4", last[1].Data)
+	}
+
+	assert.Empty(eb.last(0))
+	assert.Empty(eb.last(-1))
+}
@@ -0,0 +1,109 @@
+package device
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Comcast/webpa-common/convey"
+	"github.com/Comcast/webpa-common/logging"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/mux"
+)
+
+// DeviceStateSnapshot is a point-in-time view of everything known about a connected device
+type DeviceStateSnapshot struct {
+	ID               ID                `json:"id"`
+	RemoteAddr       string            `json:"remoteAddr,omitempty"`
+	Format           string            `json:"format"`
+	ConnectedAt      time.Time         `json:"connectedAt"`
+	UpTime           string            `json:"upTime"`
+	LastPong         *time.Time        `json:"lastPong,omitempty"`
+	BytesSent        int               `json:"bytesSent"`
+	MessagesSent     int               `json:"messagesSent"`
+	BytesReceived    int               `json:"bytesReceived"`
+	MessagesReceived int               `json:"messagesReceived"`
+	Duplications     int               `json:"duplications"`
+	Pending          int               `json:"pending"`
+	QueueDepth       int               `json:"queueDepth"`
+	Dropped          int               `json:"dropped"`
+	Metadata         convey.C          `json:"metadata,omitempty"`
+	ConnectMetadata  map[string]string `json:"connectMetadata,omitempty"`
+}
+
+// newDeviceStateSnapshot captures the current state of a device
+func newDeviceStateSnapshot(d *device) DeviceStateSnapshot {
+	snapshot := DeviceStateSnapshot{
+		ID:               d.id,
+		RemoteAddr:       d.remoteAddr,
+		Format:           d.encodeFormat().String(),
+		ConnectedAt:      d.statistics.ConnectedAt(),
+		UpTime:           d.statistics.UpTime().String(),
+		BytesSent:        d.statistics.BytesSent(),
+		MessagesSent:     d.statistics.MessagesSent(),
+		BytesReceived:    d.statistics.BytesReceived(),
+		MessagesReceived: d.statistics.MessagesReceived(),
+		Duplications:     d.statistics.Duplications(),
+		Pending:          d.Pending(),
+		QueueDepth:       d.statistics.QueueDepth(),
+		Dropped:          d.statistics.Dropped(),
+		Metadata:         d.metadata,
+		ConnectMetadata:  d.Metadata(),
+	}
+
+	if lastPong := d.lastPongTime(); !lastPong.IsZero() {
+		snapshot.LastPong = &lastPong
+	}
+
+	return snapshot
+}
+
+// StateHandler is an http.Handler that returns the full state of a single device as JSON.
+// The device name is specified as a gorilla path variable.
+type StateHandler struct {
+	Logger   log.Logger
+	Manager  Manager
+	Variable string
+}
+
+func (sh *StateHandler) logger() log.Logger {
+	if sh.Logger != nil {
+		return sh.Logger
+	}
+
+	return logging.DefaultLogger()
+}
+
+func (sh *StateHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	logger := sh.logger()
+	name, ok := mux.Vars(request)[sh.Variable]
+	if !ok {
+		logger.Log(level.Key(), level.ErrorValue(), logging.MessageKey(), "missing path variable", "variable", sh.Variable)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	id, err := ParseID(name)
+	if err != nil {
+		logger.Log(level.Key(), level.ErrorValue(), logging.MessageKey(), "unable to parse identifier", "deviceName", name, logging.ErrorKey(), err)
+		response.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := sh.Manager.DeviceState(id)
+	if err != nil {
+		response.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		logger.Log(level.Key(), level.ErrorValue(), logging.MessageKey(), "unable to marshal device state as JSON", "deviceName", name, logging.ErrorKey(), err)
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Write(data)
+}
@@ -0,0 +1,181 @@
+package device
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Comcast/webpa-common/convey"
+	"github.com/Comcast/webpa-common/convey/conveyhttp"
+	"github.com/Comcast/webpa-common/logging"
+	"github.com/Comcast/webpa-common/wrp"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceStateSnapshotQueueDepth(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		device = newDevice(ID("test"), 1, time.Now(), logging.NewTestLogger(nil, t))
+		sent   = make(chan error, 2)
+	)
+
+	// with no write pump, the first message fills the queue and the second waits to be enqueued
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := device.Send(&Request{Message: new(wrp.Message)})
+			sent <- err
+		}()
+	}
+
+	for device.Statistics().QueueDepth() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// a sender waiting for room counts toward the queue depth, but is not yet pending
+	snapshot := newDeviceStateSnapshot(device)
+	assert.Equal(1, snapshot.Pending)
+	assert.Equal(2, snapshot.QueueDepth)
+
+	device.requestClose()
+	for i := 0; i < 2; i++ {
+		assert.Equal(ErrorDeviceClosed, <-sent)
+	}
+}
+
+func TestDeviceState(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		connectWait = new(sync.WaitGroup)
+		pongs       = make(chan Interface, 10)
+		disconnects = make(chan Interface, 1)
+
+		options = &Options{
+			Logger:     logging.NewTestLogger(nil, t),
+			AuthDelay:  time.Hour,
+			PingPeriod: 100 * time.Millisecond,
+			Listeners: []Listener{
+				func(event *Event) {
+					switch event.Type {
+					case Connect:
+						connectWait.Done()
+					case Disconnect:
+						disconnects <- event.Device
+					case Pong:
+						select {
+						case pongs <- event.Device:
+						default:
+						}
+					}
+				},
+			},
+		}
+
+		manager, server, connectURL = startWebsocketServer(options)
+		dialer                      = NewDialer(options, nil)
+		id                          = testDeviceIDs[0]
+
+		header = http.Header{
+			conveyhttp.DefaultHeaderName: []string{base64.StdEncoding.EncodeToString([]byte(`{"hw-model": "test"}`))},
+		}
+	)
+
+	defer server.Close()
+
+	snapshot, err := manager.DeviceState(id)
+	assert.Equal(DeviceStateSnapshot{}, snapshot)
+	assert.Equal(ErrorDeviceNotFound, err)
+
+	connectWait.Add(1)
+	connection, _, err := dialer.Dial(connectURL, id, header)
+	require.NoError(err)
+	defer connection.Close()
+	connectWait.Wait()
+
+	snapshot, err = manager.DeviceState(id)
+	require.NoError(err)
+	assert.Equal(id, snapshot.ID)
+	assert.NotEmpty(snapshot.RemoteAddr)
+	assert.Equal(wrp.Msgpack.String(), snapshot.Format)
+	assert.False(snapshot.ConnectedAt.IsZero())
+	assert.Nil(snapshot.LastPong)
+	assert.Zero(snapshot.Pending)
+	assert.Zero(snapshot.QueueDepth)
+	assert.Zero(snapshot.Dropped)
+	assert.Equal(convey.C{"hw-model": "test"}, snapshot.Metadata)
+
+	require.NoError(manager.SetFormat(id, wrp.JSON))
+
+	// pongs are processed on the read goroutine
+	go func() {
+		var err error
+		for err == nil {
+			_, err = connection.NextReader()
+		}
+	}()
+
+	select {
+	case <-pongs:
+	case <-time.After(5 * time.Second):
+		require.Fail("The device did not respond to pings")
+	}
+
+	snapshot, err = manager.DeviceState(id)
+	require.NoError(err)
+	assert.Equal(wrp.JSON.String(), snapshot.Format)
+	if assert.NotNil(snapshot.LastPong) {
+		assert.False(snapshot.LastPong.Before(snapshot.ConnectedAt))
+	}
+
+	// the handler serves the same snapshot as JSON
+	var (
+		router  = mux.NewRouter()
+		handler = &StateHandler{
+			Logger:   logging.NewTestLogger(nil, t),
+			Manager:  manager,
+			Variable: "deviceID",
+		}
+	)
+
+	router.Handle("/device/{deviceID}/state", handler)
+
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/device/"+string(id)+"/state", nil))
+	assert.Equal(http.StatusOK, response.Code)
+	assert.Equal("application/json", response.HeaderMap.Get("Content-Type"))
+
+	var actual map[string]interface{}
+	if assert.NoError(json.Unmarshal(response.Body.Bytes(), &actual)) {
+		assert.Equal(string(id), actual["id"])
+		assert.Equal(wrp.JSON.String(), actual["format"])
+		assert.Equal(map[string]interface{}{"hw-model": "test"}, actual["metadata"])
+		assert.Contains(actual, "lastPong")
+		assert.Contains(actual, "queueDepth")
+	}
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/device/"+string(testDeviceIDs[1])+"/state", nil))
+	assert.Equal(http.StatusNotFound, response.Code)
+
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, httptest.NewRequest("GET", "/device/invalid/state", nil))
+	assert.Equal(http.StatusBadRequest, response.Code)
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(http.StatusInternalServerError, response.Code)
+
+	// wait for the server to finish with the device before the test ends
+	connection.Close()
+	select {
+	case <-disconnects:
+	case <-time.After(5 * time.Second):
+		assert.Fail("The device was not disconnected")
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -44,6 +44,19 @@ type Statistics interface {
 	// AddDuplications increments the count of duplications
 	AddDuplications(int)
 
+	// QueueDepth returns the number of messages currently waiting to be written to the device
+	QueueDepth() int
+
+	// AddQueueDepth adjusts the QueueDepth.  Enqueuing a message adds 1, and dequeuing a message subtracts 1.
+	AddQueueDepth(int)
+
+	// Dropped returns the number of messages that were never written to the device because its queue
+	// stayed full until the sender gave up
+	Dropped() int
+
+	// AddDropped increments the count of dropped messages
+	AddDropped(int)
+
 	// ConnectedAt returns the connection time at which this statistics began tracking
 	ConnectedAt() time.Time
 
@@ -66,15 +79,16 @@ func NewStatistics(now func() time.Time, connectedAt time.Time) Statistics {
 	}
 }
 
-// statistics is the internal Statistics implementation
+// statistics is the internal Statistics implementation.  Counters are accessed atomically,
+// so that reading statistics, e.g. for metrics, never contends with the pumps.
 type statistics struct {
-	lock sync.RWMutex
-
-	bytesReceived    int
-	bytesSent        int
-	messagesReceived int
-	messagesSent     int
-	duplications     int
+	bytesReceived    int64
+	bytesSent        int64
+	messagesReceived int64
+	messagesSent     int64
+	duplications     int64
+	queueDepth       int64
+	dropped          int64
 
 	now                  func() time.Time
 	connectedAt          time.Time
@@ -82,73 +96,59 @@ type statistics struct {
 }
 
 func (s *statistics) BytesReceived() int {
-	s.lock.RLock()
-	var result = s.bytesReceived
-	s.lock.RUnlock()
-
-	return result
+	return int(atomic.LoadInt64(&s.bytesReceived))
 }
 
 func (s *statistics) AddBytesReceived(delta int) {
-	s.lock.Lock()
-	s.bytesReceived += delta
-	s.lock.Unlock()
+	atomic.AddInt64(&s.bytesReceived, int64(delta))
 }
 
 func (s *statistics) BytesSent() int {
-	s.lock.RLock()
-	var result = s.bytesSent
-	s.lock.RUnlock()
-
-	return result
+	return int(atomic.LoadInt64(&s.bytesSent))
 }
 
 func (s *statistics) AddBytesSent(delta int) {
-	s.lock.Lock()
-	s.bytesSent += delta
-	s.lock.Unlock()
+	atomic.AddInt64(&s.bytesSent, int64(delta))
 }
 
 func (s *statistics) MessagesReceived() int {
-	s.lock.RLock()
-	var result = s.messagesReceived
-	s.lock.RUnlock()
-
-	return result
+	return int(atomic.LoadInt64(&s.messagesReceived))
 }
 
 func (s *statistics) AddMessagesReceived(delta int) {
-	s.lock.Lock()
-	s.messagesReceived += delta
-	s.lock.Unlock()
+	atomic.AddInt64(&s.messagesReceived, int64(delta))
 }
 
 func (s *statistics) MessagesSent() int {
-	s.lock.RLock()
-	var result = s.messagesSent
-	s.lock.RUnlock()
-
-	return result
+	return int(atomic.LoadInt64(&s.messagesSent))
 }
 
 func (s *statistics) AddMessagesSent(delta int) {
-	s.lock.Lock()
-	s.messagesSent += delta
-	s.lock.Unlock()
+	atomic.AddInt64(&s.messagesSent, int64(delta))
 }
 
 func (s *statistics) Duplications() int {
-	s.lock.RLock()
-	var result = s.duplications
-	s.lock.RUnlock()
-
-	return result
+	return int(atomic.LoadInt64(&s.duplications))
 }
 
 func (s *statistics) AddDuplications(delta int) {
-	s.lock.Lock()
-	s.duplications += delta
-	s.lock.Unlock()
+	atomic.AddInt64(&s.duplications, int64(delta))
+}
+
+func (s *statistics) QueueDepth() int {
+	return int(atomic.LoadInt64(&s.queueDepth))
+}
+
+func (s *statistics) AddQueueDepth(delta int) {
+	atomic.AddInt64(&s.queueDepth, int64(delta))
+}
+
+func (s *statistics) Dropped() int {
+	return int(atomic.LoadInt64(&s.dropped))
+}
+
+func (s *statistics) AddDropped(delta int) {
+	atomic.AddInt64(&s.dropped, int64(delta))
 }
 
 func (s *statistics) ConnectedAt() time.Time {
@@ -168,20 +168,20 @@ func (s *statistics) String() string {
 }
 
 func (s *statistics) MarshalJSON() ([]byte, error) {
-	output := bytes.NewBuffer(make([]byte, 0, 150))
-	s.lock.RLock()
+	output := bytes.NewBuffer(make([]byte, 0, 180))
 	_, err := fmt.Fprintf(
 		output,
-		`{"bytesSent": %d, "messagesSent": %d, "bytesReceived": %d, "messagesReceived": %d, "duplications": %d, "connectedAt": "%s", "upTime": "%s"}`,
-		s.bytesSent,
-		s.messagesSent,
-		s.bytesReceived,
-		s.messagesReceived,
-		s.duplications,
+		`{"bytesSent": %d, "messagesSent": %d, "bytesReceived": %d, "messagesReceived": %d, "duplications": %d, "queueDepth": %d, "dropped": %d, "connectedAt": "%s", "upTime": "%s"}`,
+		s.BytesSent(),
+		s.MessagesSent(),
+		s.BytesReceived(),
+		s.MessagesReceived(),
+		s.Duplications(),
+		s.QueueDepth(),
+		s.Dropped(),
 		s.formattedConnectedAt,
 		s.UpTime(),
 	)
 
-	s.lock.RUnlock()
 	return output.Bytes(), err
 }
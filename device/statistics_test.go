@@ -28,6 +28,8 @@ func testStatisticsInitialStateDefaultNow(t *testing.T) {
 	assert.Zero(statistics.MessagesSent())
 	assert.Zero(statistics.MessagesReceived())
 	assert.Zero(statistics.Duplications())
+	assert.Zero(statistics.QueueDepth())
+	assert.Zero(statistics.Dropped())
 	assert.Equal(expectedConnectedAt.UTC(), statistics.ConnectedAt())
 	assert.True(time.Now().Sub(expectedConnectedAt) <= statistics.UpTime())
 
@@ -42,6 +44,8 @@ func testStatisticsInitialStateDefaultNow(t *testing.T) {
 	assert.Equal(float64(0), actualJSON["bytesReceived"])
 	assert.Equal(float64(0), actualJSON["messagesReceived"])
 	assert.Equal(float64(0), actualJSON["duplications"])
+	assert.Equal(float64(0), actualJSON["queueDepth"])
+	assert.Equal(float64(0), actualJSON["dropped"])
 
 	actualConnectedAt, err := time.Parse(time.RFC3339Nano, actualJSON["connectedAt"].(string))
 	require.NoError(err)
@@ -77,6 +81,8 @@ func testStatisticsInitialStateCustomNow(t *testing.T) {
 	assert.Zero(statistics.MessagesSent())
 	assert.Zero(statistics.MessagesReceived())
 	assert.Zero(statistics.Duplications())
+	assert.Zero(statistics.QueueDepth())
+	assert.Zero(statistics.Dropped())
 	assert.Equal(expectedConnectedAt.UTC(), statistics.ConnectedAt())
 	assert.Equal(expectedUpTime, statistics.UpTime())
 
@@ -86,7 +92,7 @@ func testStatisticsInitialStateCustomNow(t *testing.T) {
 
 	assert.JSONEq(
 		fmt.Sprintf(
-			`{"duplications": 0, "bytesSent": 0, "messagesSent": 0, "bytesReceived": 0, "messagesReceived": 0, "connectedAt": "%s", "upTime": "%s"}`,
+			`{"duplications": 0, "bytesSent": 0, "messagesSent": 0, "bytesReceived": 0, "messagesReceived": 0, "queueDepth": 0, "dropped": 0, "connectedAt": "%s", "upTime": "%s"}`,
 			expectedConnectedAt.UTC().Format(time.RFC3339Nano),
 			expectedUpTime,
 		),
@@ -127,6 +133,8 @@ func testStatisticsConcurrency(t *testing.T) {
 			statistics.AddBytesReceived(v)
 			statistics.AddMessagesReceived(v)
 			statistics.AddDuplications(v)
+			statistics.AddQueueDepth(v)
+			statistics.AddDropped(v)
 		}(v)
 	}
 
@@ -138,6 +146,8 @@ func testStatisticsConcurrency(t *testing.T) {
 	assert.Equal(expectedValue, statistics.BytesReceived())
 	assert.Equal(expectedValue, statistics.MessagesReceived())
 	assert.Equal(expectedValue, statistics.Duplications())
+	assert.Equal(expectedValue, statistics.QueueDepth())
+	assert.Equal(expectedValue, statistics.Dropped())
 	assert.Equal(expectedConnectedAt.UTC(), statistics.ConnectedAt())
 	assert.Equal(expectedUpTime, statistics.UpTime())
 
@@ -147,7 +157,9 @@ func testStatisticsConcurrency(t *testing.T) {
 
 	assert.JSONEq(
 		fmt.Sprintf(
-			`{"duplications": %d, "bytesSent": %d, "messagesSent": %d, "bytesReceived": %d, "messagesReceived": %d, "connectedAt": "%s", "upTime": "%s"}`,
+			`{"duplications": %d, "bytesSent": %d, "messagesSent": %d, "bytesReceived": %d, "messagesReceived": %d, "queueDepth": %d, "dropped": %d, "connectedAt": "%s", "upTime": "%s"}`,
+			expectedValue,
+			expectedValue,
 			expectedValue,
 			expectedValue,
 			expectedValue,
@@ -9,20 +9,25 @@ import (
 
 	"github.com/Comcast/webpa-common/httperror"
 	"github.com/Comcast/webpa-common/wrp"
+	"github.com/Comcast/webpa-common/wrp/wrphttp"
 )
 
 // Request represents a single device Request, carrying routing information and message contents.
+//
+// A Request is never modified by the devices it is sent to, so the same Request may be sent to any number
+// of devices, e.g. when broadcasting an event.  Supplying Contents in the format devices use avoids encoding
+// Message separately for each device.
 type Request struct {
 	// Message is the original, decoded WRP message containing the routing information.  When sending a request
 	// through Manager.Route, this field is required and must also implement wrp.Routable.
 	Message wrp.Typed
 
-	// Format is the WRP format of the Contents member.  If Format is not JSON, then Routing
-	// will be encoded prior to sending to devices.
+	// Format is the WRP format of the Contents member.
 	Format wrp.Format
 
-	// Contents is the encoded form of Routing in Format format.  If this member is of 0 length,
-	// then Routing will be encoded prior to sending to devices.
+	// Contents is the encoded form of Message in Format format.  These bytes are written to a device
+	// as is when Format matches the device's format.  If this member is of 0 length or Format does not
+	// match, then Message is encoded for that device prior to sending.
 	Contents []byte
 
 	// ctx is the API context for this request, which can be nil.  Normally, it's best to
@@ -30,6 +35,18 @@ type Request struct {
 	ctx context.Context
 }
 
+// NewEntityRequest creates a Request from a wrphttp.Entity, such as one decoded from an HTTP request.
+// The Entity's preserved Contents are written as is to devices whose format matches the Entity's, and its
+// Message is only encoded for devices using a different format.  The returned Request shares the Entity's
+// Message and Contents, so the Entity must not be modified while the Request is in use.
+func NewEntityRequest(entity *wrphttp.Entity) *Request {
+	return &Request{
+		Message:  &entity.Message,
+		Format:   entity.Format,
+		Contents: entity.Contents,
+	}
+}
+
 // Transactional tests if Message is Routable and, if so, returns the transactional information
 // from the request.  This method returns a tuple containing the transaction key (if any) combined with
 // wheither this request represents part of a transaction.
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/Comcast/webpa-common/wrp"
+	"github.com/Comcast/webpa-common/wrp/wrphttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -62,9 +63,34 @@ func testRequestID(t *testing.T) {
 	assert.Error(err)
 }
 
+func testRequestEntity(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		entity = &wrphttp.Entity{
+			Format: wrp.JSON,
+			Message: wrp.Message{
+				Type:        wrp.SimpleEventMessageType,
+				Source:      "dns:server.com",
+				Destination: "mac:123412341234",
+			},
+		}
+	)
+
+	entity.Contents = wrp.MustEncode(&entity.Message, wrp.JSON)
+	request := NewEntityRequest(entity)
+	assert.True(&entity.Message == request.Message)
+	assert.Equal(wrp.JSON, request.Format)
+	assert.Equal(entity.Contents, request.Contents)
+
+	id, err := request.ID()
+	assert.Equal(ID("mac:123412341234"), id)
+	assert.NoError(err)
+}
+
 func TestRequest(t *testing.T) {
 	t.Run("Context", testRequestContext)
 	t.Run("ID", testRequestID)
+	t.Run("Entity", testRequestEntity)
 }
 
 func testDecodeRequest(t *testing.T, message wrp.Routable, format wrp.Format) {
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Readiness coordinates the startup of several subsystems.  Each subsystem registers a named signal,
+// and reports ready through that signal once it has finished starting.  For example, a server might register
+// one signal that is reported after required keys have been resolved and another that is reported after the server
+// has registered itself in service discovery.  The server is ready only when every registered signal has been reported.
+//
+// A Readiness does not observe any subsystem itself.  Code which resolves keys, registers with service discovery,
+// or starts anything else is responsible for invoking its reporter once that step has succeeded.
+//
+// A Readiness is an http.Handler suitable for use as a readiness probe.
+type Readiness struct {
+	lock       sync.RWMutex
+	generation uint64
+	signals    map[string]readinessSignal
+}
+
+// readinessSignal is the state of a single named signal.  The generation identifies the
+// registration which produced this signal, so that stale reporters can be ignored.
+type readinessSignal struct {
+	generation uint64
+	ready      bool
+}
+
+// NewReadiness creates a Readiness with no signals.  Until signals are registered, a Readiness reports ready.
+func NewReadiness() *Readiness {
+	return &Readiness{
+		signals: make(map[string]readinessSignal),
+	}
+}
+
+// Register adds a named signal to this Readiness, returning the function which reports that signal as ready.
+// The returned function is idempotent and safe for concurrent use.  Registering the same name more than once
+// resets that signal to not ready, and only the function returned by the latest registration can report it.
+func (r *Readiness) Register(name string) func() {
+	r.lock.Lock()
+	r.generation++
+	generation := r.generation
+	r.signals[name] = readinessSignal{generation: generation}
+	r.lock.Unlock()
+
+	return func() {
+		r.lock.Lock()
+		if signal, ok := r.signals[name]; ok && signal.generation == generation {
+			signal.ready = true
+			r.signals[name] = signal
+		}
+
+		r.lock.Unlock()
+	}
+}
+
+// Ready tests whether all registered signals have been reported
+func (r *Readiness) Ready() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, signal := range r.signals {
+		if !signal.ready {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Pending returns the sorted names of the signals which have not yet been reported
+func (r *Readiness) Pending() []string {
+	r.lock.RLock()
+	pending := make([]string, 0, len(r.signals))
+	for name, signal := range r.signals {
+		if !signal.ready {
+			pending = append(pending, name)
+		}
+	}
+
+	r.lock.RUnlock()
+	sort.Strings(pending)
+	return pending
+}
+
+// ServeHTTP writes a JSON readiness report.  The response status is http.StatusOK if all signals have been
+// reported and http.StatusServiceUnavailable otherwise.
+func (r *Readiness) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	var (
+		pending = r.Pending()
+		report  = struct {
+			Ready   bool     `json:"ready"`
+			Pending []string `json:"pending"`
+		}{len(pending) == 0, pending}
+	)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	if report.Ready {
+		response.WriteHeader(http.StatusOK)
+	} else {
+		response.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	response.Write(data)
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	var (
+		assert    = assert.New(t)
+		readiness = NewReadiness()
+	)
+
+	assert.True(readiness.Ready())
+	assert.Empty(readiness.Pending())
+
+	var (
+		keysReady         = readiness.Register("keys")
+		registrationReady = readiness.Register("registration")
+	)
+
+	assert.False(readiness.Ready())
+	assert.Equal([]string{"keys", "registration"}, readiness.Pending())
+
+	response := httptest.NewRecorder()
+	readiness.ServeHTTP(response, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(http.StatusServiceUnavailable, response.Code)
+	assert.Equal("application/json", response.HeaderMap.Get("Content-Type"))
+	assert.JSONEq(`{"ready": false, "pending": ["keys", "registration"]}`, response.Body.String())
+
+	registrationReady()
+	registrationReady()
+	assert.False(readiness.Ready())
+	assert.Equal([]string{"keys"}, readiness.Pending())
+
+	keysReady()
+	assert.True(readiness.Ready())
+	assert.Empty(readiness.Pending())
+
+	response = httptest.NewRecorder()
+	readiness.ServeHTTP(response, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(http.StatusOK, response.Code)
+	assert.JSONEq(`{"ready": true, "pending": []}`, response.Body.String())
+}
+
+func TestReadinessReregister(t *testing.T) {
+	var (
+		assert    = assert.New(t)
+		readiness = NewReadiness()
+		stale     = readiness.Register("keys")
+		current   = readiness.Register("keys")
+	)
+
+	// the reporter from the earlier registration cannot report the new one
+	stale()
+	assert.False(readiness.Ready())
+	assert.Equal([]string{"keys"}, readiness.Pending())
+
+	current()
+	assert.True(readiness.Ready())
+
+	// nor can it undo or affect a later registration
+	readiness.Register("keys")
+	stale()
+	current()
+	assert.False(readiness.Ready())
+}
@@ -14,6 +14,9 @@ const (
 
 // Cache is a Resolver type which provides caching for keys based on keyID.
 //
+// A cached key which carries an expiry, such as a key parsed from a certificate, is never
+// returned past that expiry.  Instead, it is resolved again from the delegate Resolver.
+//
 // All implementations will block the first time a particular key is accessed
 // and will initialize the value for that key.  Thereafter, all updates happen
 // in a separate goroutine.  This allows HTTP transactions to avoid paying
@@ -36,6 +39,21 @@ type Cache interface {
 	UpdateKeys() (int, []error)
 }
 
+// expiringPair is implemented by Pair types which are only trusted up to a point in time,
+// such as those returned by NewCertificateParser
+type expiringPair interface {
+	NotAfter() time.Time
+}
+
+// expired tests whether a cached pair can no longer be trusted
+func expired(pair Pair) bool {
+	if e, ok := pair.(expiringPair); ok {
+		return !time.Now().Before(e.NotAfter())
+	}
+
+	return false
+}
+
 // basicCache contains the internal members common to all cache implementations
 type basicCache struct {
 	delegate   Resolver
@@ -67,10 +85,10 @@ type singleCache struct {
 func (cache *singleCache) ResolveKey(keyID string) (pair Pair, err error) {
 	var ok bool
 	pair, ok = cache.load().(Pair)
-	if !ok {
+	if !ok || expired(pair) {
 		cache.update(func() {
 			pair, ok = cache.load().(Pair)
-			if !ok {
+			if !ok || expired(pair) {
 				pair, err = cache.delegate.ResolveKey(keyID)
 				if err == nil {
 					cache.store(pair)
@@ -114,6 +132,10 @@ func (cache *multiCache) fetchPair(keyID string) (pair Pair, ok bool) {
 		pair, ok = pairs[keyID]
 	}
 
+	if ok && expired(pair) {
+		pair, ok = nil, false
+	}
+
 	return
 }
 
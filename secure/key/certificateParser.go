@@ -0,0 +1,115 @@
+package key
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+)
+
+const (
+	// CertificateBlockType is the PEM block type of X.509 certificates
+	CertificateBlockType = "CERTIFICATE"
+)
+
+var (
+	ErrorCertificateRequired        = errors.New("Keys must be PEM-encoded certificates")
+	ErrorCertificateHasNoPrivateKey = errors.New("Certificates cannot supply private keys")
+)
+
+// certificatePair is an rsaPair whose public key came from a certificate, and is
+// therefore only trusted until that certificate expires
+type certificatePair struct {
+	rsaPair
+	notAfter time.Time
+}
+
+// NotAfter returns the expiry of the certificate which supplied this pair's public key
+func (cp *certificatePair) NotAfter() time.Time {
+	return cp.notAfter
+}
+
+// certificateParser is a Parser which only trusts public keys embedded in certificates
+// whose chains verify against a set of trusted roots.
+type certificateParser struct {
+	roots *x509.CertPool
+}
+
+// NewCertificateParser returns a Parser that accepts PEM-encoded X.509 certificates.  The first
+// certificate in the data is the one whose public key is returned, and any subsequent certificates
+// are used as intermediates when verifying its chain.  Keys whose chains do not verify against
+// the given roots, including expired certificates, are rejected.  If roots is nil, the system's
+// root certificates are used.
+//
+// This Parser can be used with any Resolver, e.g. by setting ResolverFactory.Parser.  Since certificates
+// never carry private keys, the returned Parser rejects any Purpose which requires a private key.
+//
+// Chains are only verified when a key is parsed.  The returned Pair records its certificate's NotAfter,
+// and the caching Resolvers in this package treat such a Pair as a cache miss once that time has passed.
+// Callers which hold onto a Pair outside of a Cache must check NotAfter themselves.
+func NewCertificateParser(roots *x509.CertPool) Parser {
+	return &certificateParser{
+		roots: roots,
+	}
+}
+
+func (p *certificateParser) String() string {
+	return "certificateParser"
+}
+
+func (p *certificateParser) ParseKey(purpose Purpose, data []byte) (Pair, error) {
+	if purpose.RequiresPrivateKey() {
+		return nil, ErrorCertificateHasNoPrivateKey
+	}
+
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		} else if block.Type != CertificateBlockType {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, ErrorCertificateRequired
+	}
+
+	options := x509.VerifyOptions{
+		Roots:         p.roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	for _, intermediate := range certificates[1:] {
+		options.Intermediates.AddCert(intermediate)
+	}
+
+	leaf := certificates[0]
+	if _, err := leaf.Verify(options); err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := leaf.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrorNotRSAPublicKey
+	}
+
+	return &certificatePair{
+		rsaPair: rsaPair{
+			purpose: purpose,
+			public:  publicKey,
+			private: nil,
+		},
+		notAfter: leaf.NotAfter,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package key
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate is a generated certificate together with its private key
+type testCertificate struct {
+	certificate *x509.Certificate
+	privateKey  *rsa.PrivateKey
+	pem         []byte
+}
+
+// newTestCertificate generates a certificate signed by the given issuer.  If issuer is nil,
+// the certificate is self-signed.
+func newTestCertificate(t *testing.T, serial int64, isCA bool, notAfter time.Time, issuer *testCertificate) *testCertificate {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "test certificate"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	var (
+		parent    = template
+		signerKey = privateKey
+	)
+
+	if issuer != nil {
+		parent = issuer.certificate
+		signerKey = issuer.privateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &privateKey.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		certificate: certificate,
+		privateKey:  privateKey,
+		pem:         pem.EncodeToMemory(&pem.Block{Type: CertificateBlockType, Bytes: der}),
+	}
+}
+
+func TestCertificateParser(t *testing.T) {
+	var (
+		notAfter = time.Now().Add(time.Hour)
+		ca       = newTestCertificate(t, 1, true, notAfter, nil)
+		roots    = x509.NewCertPool()
+		parser   = NewCertificateParser(roots)
+	)
+
+	roots.AddCert(ca.certificate)
+
+	t.Run("Trusted", func(t *testing.T) {
+		var (
+			assert  = assert.New(t)
+			trusted = newTestCertificate(t, 2, false, notAfter, ca)
+		)
+
+		pair, err := parser.ParseKey(PurposeVerify, trusted.pem)
+		assert.NoError(err)
+		if assert.NotNil(pair) {
+			assert.Equal(PurposeVerify, pair.Purpose())
+			assert.Equal(&trusted.privateKey.PublicKey, pair.Public())
+			assert.False(pair.HasPrivate())
+		}
+	})
+
+	t.Run("Intermediate", func(t *testing.T) {
+		var (
+			assert       = assert.New(t)
+			intermediate = newTestCertificate(t, 3, true, notAfter, ca)
+			leaf         = newTestCertificate(t, 4, false, notAfter, intermediate)
+		)
+
+		pair, err := parser.ParseKey(PurposeVerify, leaf.pem)
+		assert.Nil(pair)
+		assert.Error(err)
+
+		pair, err = parser.ParseKey(PurposeVerify, append(leaf.pem, intermediate.pem...))
+		assert.NoError(err)
+		if assert.NotNil(pair) {
+			assert.Equal(&leaf.privateKey.PublicKey, pair.Public())
+		}
+	})
+
+	t.Run("SelfSigned", func(t *testing.T) {
+		var (
+			assert     = assert.New(t)
+			selfSigned = newTestCertificate(t, 5, false, notAfter, nil)
+		)
+
+		pair, err := parser.ParseKey(PurposeVerify, selfSigned.pem)
+		assert.Nil(pair)
+		assert.Error(err)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		var (
+			assert  = assert.New(t)
+			expired = newTestCertificate(t, 6, false, time.Now().Add(-time.Minute), ca)
+		)
+
+		pair, err := parser.ParseKey(PurposeVerify, expired.pem)
+		assert.Nil(pair)
+		assert.Error(err)
+	})
+
+	t.Run("NotACertificate", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, data := range [][]byte{[]byte("this is not PEM"), ca.pem[:0]} {
+			pair, err := parser.ParseKey(PurposeVerify, data)
+			assert.Nil(pair)
+			assert.Equal(ErrorCertificateRequired, err)
+		}
+	})
+
+	t.Run("PrivateKeyPurpose", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, purpose := range []Purpose{PurposeSign, PurposeEncrypt} {
+			pair, err := parser.ParseKey(purpose, ca.pem)
+			assert.Nil(pair)
+			assert.Equal(ErrorCertificateHasNoPrivateKey, err)
+		}
+	})
+}
+
+func TestCertificatePairCacheExpiry(t *testing.T) {
+	testData := []struct {
+		name  string
+		cache func(Resolver) Cache
+	}{
+		{"Single", func(delegate Resolver) Cache { return &singleCache{basicCache{delegate: delegate}} }},
+		{"Multi", func(delegate Resolver) Cache { return &multiCache{basicCache{delegate: delegate}} }},
+	}
+
+	for _, record := range testData {
+		t.Run(record.name, func(t *testing.T) {
+			var (
+				assert   = assert.New(t)
+				resolver = new(MockResolver)
+				cache    = record.cache(resolver)
+
+				expiredPair = &certificatePair{notAfter: time.Now().Add(-time.Minute)}
+				currentPair = &certificatePair{notAfter: time.Now().Add(time.Hour)}
+			)
+
+			resolver.On("ResolveKey", "test").Return(expiredPair, nil).Once()
+			resolver.On("ResolveKey", "test").Return(currentPair, nil).Once()
+
+			pair, err := cache.ResolveKey("test")
+			assert.Equal(expiredPair, pair)
+			assert.NoError(err)
+
+			for repeat := 0; repeat < 2; repeat++ {
+				pair, err = cache.ResolveKey("test")
+				assert.Equal(currentPair, pair)
+				assert.NoError(err)
+			}
+
+			resolver.AssertExpectations(t)
+		})
+	}
+}
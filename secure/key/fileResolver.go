@@ -0,0 +1,219 @@
+package key
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Comcast/webpa-common/logging"
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	ErrorNoSuchKey = errors.New("No key exists with that key id")
+)
+
+// FileResolver is a Resolver backed by a directory of key files.  Each file in the directory holds either
+// a single key, whose id is the file's name, or a JWK Set, in which each key's id is its "kid".  Keys are
+// held in memory and the directory is rescanned whenever an external process changes it.
+//
+// A rescan re-reads every key file and drops the keys of files that no longer exist.  This supports
+// rotation through symlinks, such as a mounted Kubernetes secret, where every key changes at once through
+// an atomic swap of a single symlink and the key files themselves never produce their own events.
+//
+// Files holding JSON are parsed as a JWK or a JWK Set, which may only hold RSA public keys.  All other
+// files are parsed by the Parser, which for DefaultParser means PEM.
+type FileResolver interface {
+	Resolver
+
+	// Close stops watching the directory for changes.  Keys already loaded are still
+	// resolvable after this method is called, but they will no longer be updated.
+	Close() error
+}
+
+// NewFileResolver loads all the keys in the given directory and returns a FileResolver
+// that watches that directory for changes.  Every file must parse as a key for the given Purpose,
+// or this function returns an error.  If parser is nil, DefaultParser is used.  If logger is nil,
+// logging.DefaultLogger() is used.
+//
+// Errors that occur while reloading keys or watching the directory are logged, since there is
+// no caller to return them to.  The keys from a file that fails to reload are left in place.
+//
+// Subdirectories and files whose names start with '.' are ignored, though changes to them still
+// trigger a rescan.  Symlinks to files are followed.
+func NewFileResolver(dir string, purpose Purpose, parser Parser, logger log.Logger) (FileResolver, error) {
+	if parser == nil {
+		parser = DefaultParser
+	}
+
+	if logger == nil {
+		logger = logging.DefaultLogger()
+	}
+
+	r := &fileResolver{
+		errorLog: logging.Error(logger, "dir", dir),
+		basicResolver: basicResolver{
+			parser:  parser,
+			purpose: purpose,
+		},
+		dir:   dir,
+		files: make(map[string]map[string]Pair),
+		pairs: make(map[string]Pair),
+	}
+
+	if err := r.scan(true); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if r.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, err
+	}
+
+	if err := r.watcher.Add(dir); err != nil {
+		r.watcher.Close()
+		return nil, err
+	}
+
+	go r.watch()
+	return r, nil
+}
+
+// isKeyFile tests if the given file name is eligible to hold a key
+func isKeyFile(name string) bool {
+	return len(name) > 0 && name[0] != '.'
+}
+
+// fileResolver is the internal FileResolver implementation
+type fileResolver struct {
+	basicResolver
+
+	dir      string
+	watcher  *fsnotify.Watcher
+	errorLog log.Logger
+
+	// files holds the keys loaded from each file name.  It is only used by the goroutine scanning the directory.
+	files map[string]map[string]Pair
+
+	lock  sync.RWMutex
+	pairs map[string]Pair
+}
+
+func (r *fileResolver) ResolveKey(keyId string) (Pair, error) {
+	r.lock.RLock()
+	pair, ok := r.pairs[keyId]
+	r.lock.RUnlock()
+
+	if !ok {
+		return nil, ErrorNoSuchKey
+	}
+
+	return pair, nil
+}
+
+func (r *fileResolver) Close() error {
+	return r.watcher.Close()
+}
+
+// loadFile reads and parses the keys in the given file
+func (r *fileResolver) loadFile(name string) (map[string]Pair, error) {
+	data, err := ioutil.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if isJSON(data) {
+		return parseJWKs(r.purpose, name, data)
+	}
+
+	pair, err := r.parseKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]Pair{name: pair}, nil
+}
+
+// scan reads every key file in the directory and replaces the in-memory keys.  During the initial scan,
+// any error is returned.  Afterwards, errors are logged and a file that fails to load keeps its previous keys.
+func (r *fileResolver) scan(initial bool) error {
+	entries, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		if initial {
+			return err
+		}
+
+		r.errorLog.Log(logging.MessageKey(), "unable to read key directory", logging.ErrorKey(), err)
+		return nil
+	}
+
+	files := make(map[string]map[string]Pair, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !isKeyFile(name) {
+			continue
+		}
+
+		// stat the path rather than using the entry, so that symlinks are followed.  a dangling
+		// symlink is a key that has been rotated away.
+		if info, err := os.Stat(filepath.Join(r.dir, name)); err != nil || info.IsDir() {
+			continue
+		}
+
+		keys, err := r.loadFile(name)
+		if err != nil {
+			if initial {
+				return err
+			}
+
+			// a file that fails to load is usually in the middle of being rewritten,
+			// so the previous keys are kept until a subsequent rescan supplies valid ones
+			r.errorLog.Log(logging.MessageKey(), "unable to reload key", "keyId", name, logging.ErrorKey(), err)
+			if previous, ok := r.files[name]; ok {
+				files[name] = previous
+			}
+
+			continue
+		}
+
+		files[name] = keys
+	}
+
+	pairs := make(map[string]Pair, len(files))
+	for _, keys := range files {
+		for keyId, pair := range keys {
+			pairs[keyId] = pair
+		}
+	}
+
+	r.files = files
+	r.lock.Lock()
+	r.pairs = pairs
+	r.lock.Unlock()
+	return nil
+}
+
+// watch is the goroutine that applies file system changes to the in-memory keys.  Any change
+// in the directory causes a rescan.  This goroutine exits when the watcher is closed.
+func (r *fileResolver) watch() {
+	for {
+		select {
+		case _, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+
+			r.scan(false)
+
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			r.errorLog.Log(logging.MessageKey(), "error while watching key directory", logging.ErrorKey(), err)
+		}
+	}
+}
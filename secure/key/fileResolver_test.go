@@ -0,0 +1,255 @@
+package key
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Comcast/webpa-common/logging"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPublicKeyPEM generates a new RSA key and returns the PEM encoding of its public key
+func newPublicKeyPEM(t *testing.T) (*rsa.PublicKey, []byte) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	require.NoError(t, err)
+
+	return &privateKey.PublicKey, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// awaitPublicKey polls the resolver until the given key id resolves to the expected public key,
+// or until a timeout elapses.  A nil expected key waits for the key id to be removed.
+func awaitPublicKey(resolver Resolver, keyId string, expected interface{}) bool {
+	timeout := time.After(5 * time.Second)
+	for {
+		pair, err := resolver.ResolveKey(keyId)
+		if expected == nil && err == ErrorNoSuchKey {
+			return true
+		} else if expected != nil && err == nil && assert.ObjectsAreEqual(expected, pair.Public()) {
+			return true
+		}
+
+		select {
+		case <-timeout:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestNewFileResolver(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+	)
+
+	dir, err := ioutil.TempDir("", "TestNewFileResolver")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	original, err := ioutil.ReadFile(publicKeyFilePath)
+	require.NoError(err)
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, keyId), original, 0644))
+	require.NoError(os.Mkdir(filepath.Join(dir, "subdirectory"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("this is not a key"), 0644))
+
+	expectedPair, err := DefaultParser.ParseKey(PurposeVerify, original)
+	require.NoError(err)
+
+	resolver, err := NewFileResolver(dir, PurposeVerify, nil, nil)
+	require.NoError(err)
+	require.NotNil(resolver)
+	defer resolver.Close()
+
+	pair, err := resolver.ResolveKey(keyId)
+	require.NoError(err)
+	assert.Equal(PurposeVerify, pair.Purpose())
+	assert.Equal(expectedPair.Public(), pair.Public())
+	assert.False(pair.HasPrivate())
+
+	pair, err = resolver.ResolveKey("nosuchkey")
+	assert.Nil(pair)
+	assert.Equal(ErrorNoSuchKey, err)
+
+	t.Run("Modify", func(t *testing.T) {
+		rotated, rotatedPEM := newPublicKeyPEM(t)
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, keyId), rotatedPEM, 0644))
+		assert.True(awaitPublicKey(resolver, keyId, rotated), "The modified key was not reloaded")
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		added, addedPEM := newPublicKeyPEM(t)
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, "added"), addedPEM, 0644))
+		assert.True(awaitPublicKey(resolver, "added", added), "The new key was not loaded")
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		require.NoError(os.Remove(filepath.Join(dir, "added")))
+		assert.True(awaitPublicKey(resolver, "added", nil), "The removed key is still resolvable")
+	})
+}
+
+func TestNewFileResolverBadKey(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+	)
+
+	dir, err := ioutil.TempDir("", "TestNewFileResolverBadKey")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "bad"), []byte("this is not a key"), 0644))
+
+	resolver, err := NewFileResolver(dir, PurposeVerify, nil, nil)
+	assert.Nil(resolver)
+	assert.Equal(ErrorPEMRequired, err)
+}
+
+func TestNewFileResolverReloadError(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		logged  = make(chan map[interface{}]interface{}, 10)
+		logger  = log.LoggerFunc(func(keyvals ...interface{}) error {
+			entry := make(map[interface{}]interface{}, len(keyvals)/2)
+			for i := 0; i+1 < len(keyvals); i += 2 {
+				entry[keyvals[i]] = keyvals[i+1]
+			}
+
+			select {
+			case logged <- entry:
+			default:
+			}
+
+			return nil
+		})
+	)
+
+	dir, err := ioutil.TempDir("", "TestNewFileResolverReloadError")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	original, err := ioutil.ReadFile(publicKeyFilePath)
+	require.NoError(err)
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, keyId), original, 0644))
+
+	expectedPair, err := DefaultParser.ParseKey(PurposeVerify, original)
+	require.NoError(err)
+
+	resolver, err := NewFileResolver(dir, PurposeVerify, nil, logger)
+	require.NoError(err)
+	require.NotNil(resolver)
+	defer resolver.Close()
+
+	// a rotated key that fails to parse is logged, and the previous key is kept
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, keyId), []byte("this is not a key"), 0644))
+	select {
+	case entry := <-logged:
+		assert.Equal("unable to reload key", entry[logging.MessageKey()])
+		assert.Equal(keyId, entry["keyId"])
+		assert.Equal(dir, entry["dir"])
+		assert.Equal(ErrorPEMRequired, entry[logging.ErrorKey()])
+	case <-time.After(5 * time.Second):
+		assert.Fail("The reload error was not logged")
+	}
+
+	pair, err := resolver.ResolveKey(keyId)
+	require.NoError(err)
+	assert.Equal(expectedPair.Public(), pair.Public())
+}
+
+func TestNewFileResolverSymlinkRotation(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+	)
+
+	dir, err := ioutil.TempDir("", "TestNewFileResolverSymlinkRotation")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// lay out the directory the way a mounted Kubernetes secret is:  each key is a symlink through
+	// the ..data symlink, which points at a hidden directory holding the current version of every key
+	var (
+		originalKey, originalPEM = newPublicKeyPEM(t)
+		otherKey, otherPEM       = newPublicKeyPEM(t)
+		rotatedKey, rotatedPEM   = newPublicKeyPEM(t)
+	)
+
+	require.NoError(os.Mkdir(filepath.Join(dir, "..version1"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "..version1", keyId), originalPEM, 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "..version1", "other"), otherPEM, 0644))
+	require.NoError(os.Symlink("..version1", filepath.Join(dir, "..data")))
+	require.NoError(os.Symlink(filepath.Join("..data", keyId), filepath.Join(dir, keyId)))
+	require.NoError(os.Symlink(filepath.Join("..data", "other"), filepath.Join(dir, "other")))
+
+	resolver, err := NewFileResolver(dir, PurposeVerify, nil, nil)
+	require.NoError(err)
+	require.NotNil(resolver)
+	defer resolver.Close()
+
+	assert.True(awaitPublicKey(resolver, keyId, originalKey))
+	assert.True(awaitPublicKey(resolver, "other", otherKey))
+
+	// rotate by atomically swapping the ..data symlink to a version without the other key
+	require.NoError(os.Mkdir(filepath.Join(dir, "..version2"), 0755))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "..version2", keyId), rotatedPEM, 0644))
+	require.NoError(os.Symlink("..version2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	require.NoError(os.RemoveAll(filepath.Join(dir, "..version1")))
+
+	assert.True(awaitPublicKey(resolver, keyId, rotatedKey), "The rotated key was not reloaded")
+	assert.True(awaitPublicKey(resolver, "other", nil), "The key missing from the rotated version is still resolvable")
+}
+
+func TestNewFileResolverJWKS(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		singleKey, single = newPublicJWK(t, "")
+		firstKey, first   = newPublicJWK(t, "first")
+		secondKey, second = newPublicJWK(t, "second")
+	)
+
+	dir, err := ioutil.TempDir("", "TestNewFileResolverJWKS")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "single"), mustMarshalJSON(t, single), 0644))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "jwks"), mustMarshalJSON(t, map[string]interface{}{"keys": []jsonWebKey{first}}), 0644))
+
+	resolver, err := NewFileResolver(dir, PurposeVerify, nil, nil)
+	require.NoError(err)
+	require.NotNil(resolver)
+	defer resolver.Close()
+
+	assert.True(awaitPublicKey(resolver, "single", singleKey))
+	assert.True(awaitPublicKey(resolver, "first", firstKey))
+	assert.True(awaitPublicKey(resolver, "jwks", nil))
+
+	// keys added to and removed from a JWK Set are picked up when the set is rewritten
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "jwks"), mustMarshalJSON(t, map[string]interface{}{"keys": []jsonWebKey{second}}), 0644))
+	assert.True(awaitPublicKey(resolver, "second", secondKey), "The added JWK was not loaded")
+	assert.True(awaitPublicKey(resolver, "first", nil), "The removed JWK is still resolvable")
+}
+
+func TestNewFileResolverMissingDirectory(t *testing.T) {
+	assert := assert.New(t)
+
+	resolver, err := NewFileResolver("/this/does/not/exist", PurposeVerify, nil, nil)
+	assert.Nil(resolver)
+	assert.Error(err)
+}
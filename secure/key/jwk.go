@@ -0,0 +1,105 @@
+package key
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrorJWKHasNoPrivateKey = errors.New("JWKs cannot supply private keys")
+	ErrorUnsupportedJWK     = errors.New("Only RSA public JWKs are supported")
+	ErrorJWKKeyIDRequired   = errors.New("Each key in a JWK Set must have a kid")
+)
+
+// jsonWebKey is the subset of RFC 7517 JSON Web Key members needed for RSA public keys
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// jsonWebKeyFile is either a single JWK or a JWK Set, as found in a key file
+type jsonWebKeyFile struct {
+	jsonWebKey
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// isJSON tests if the given key data holds a JSON object rather than, e.g., PEM
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// parseJWKs parses either a single JWK or a JWK Set.  A single JWK is stored under the given
+// default key id, while each key in a JWK Set is stored under its own kid.
+func parseJWKs(purpose Purpose, defaultKeyId string, data []byte) (map[string]Pair, error) {
+	if purpose.RequiresPrivateKey() {
+		return nil, ErrorJWKHasNoPrivateKey
+	}
+
+	var file jsonWebKeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Keys == nil {
+		pair, err := file.jsonWebKey.toPair(purpose)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]Pair{defaultKeyId: pair}, nil
+	}
+
+	pairs := make(map[string]Pair, len(file.Keys))
+	for _, key := range file.Keys {
+		if len(key.KeyID) == 0 {
+			return nil, ErrorJWKKeyIDRequired
+		}
+
+		pair, err := key.toPair(purpose)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs[key.KeyID] = pair
+	}
+
+	return pairs, nil
+}
+
+// toPair converts this JWK into a public key Pair
+func (jwk jsonWebKey) toPair(purpose Purpose) (Pair, error) {
+	if jwk.KeyType != "RSA" {
+		return nil, ErrorUnsupportedJWK
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+		return nil, ErrorUnsupportedJWK
+	}
+
+	return &rsaPair{
+		purpose: purpose,
+		public: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exponent.Int64()),
+		},
+		private: nil,
+	}, nil
+}
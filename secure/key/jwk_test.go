@@ -0,0 +1,91 @@
+package key
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPublicJWK generates a new RSA key and returns its public key along with its JWK form
+func newPublicJWK(t *testing.T, keyId string) (*rsa.PublicKey, jsonWebKey) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	return &privateKey.PublicKey, jsonWebKey{
+		KeyType: "RSA",
+		KeyID:   keyId,
+		N:       base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+	}
+}
+
+func mustMarshalJSON(t *testing.T, value interface{}) []byte {
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseJWKs(t *testing.T) {
+	var (
+		firstKey, first   = newPublicJWK(t, "first")
+		secondKey, second = newPublicJWK(t, "second")
+	)
+
+	t.Run("Single", func(t *testing.T) {
+		assert := assert.New(t)
+		pairs, err := parseJWKs(PurposeVerify, "default", mustMarshalJSON(t, first))
+		assert.NoError(err)
+		if assert.Len(pairs, 1) {
+			assert.Equal(firstKey, pairs["default"].Public())
+			assert.Equal(PurposeVerify, pairs["default"].Purpose())
+			assert.False(pairs["default"].HasPrivate())
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		assert := assert.New(t)
+		pairs, err := parseJWKs(PurposeVerify, "default", mustMarshalJSON(t, map[string]interface{}{"keys": []jsonWebKey{first, second}}))
+		assert.NoError(err)
+		if assert.Len(pairs, 2) {
+			assert.Equal(firstKey, pairs["first"].Public())
+			assert.Equal(secondKey, pairs["second"].Public())
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		var (
+			assert       = assert.New(t)
+			noKeyId      = first
+			notRSA       = first
+			badModulus   = first
+			zeroExponent = first
+		)
+
+		noKeyId.KeyID = ""
+		notRSA.KeyType = "EC"
+		badModulus.N = "this is not base64!"
+		zeroExponent.E = ""
+
+		for _, data := range [][]byte{
+			[]byte("{this is not JSON"),
+			mustMarshalJSON(t, map[string]interface{}{"keys": []jsonWebKey{noKeyId}}),
+			mustMarshalJSON(t, notRSA),
+			mustMarshalJSON(t, badModulus),
+			mustMarshalJSON(t, zeroExponent),
+		} {
+			pairs, err := parseJWKs(PurposeVerify, "default", data)
+			assert.Nil(pairs)
+			assert.Error(err)
+		}
+
+		pairs, err := parseJWKs(PurposeSign, "default", mustMarshalJSON(t, first))
+		assert.Nil(pairs)
+		assert.Equal(ErrorJWKHasNoPrivateKey, err)
+	})
+}
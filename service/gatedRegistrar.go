@@ -0,0 +1,92 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Comcast/webpa-common/logging"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/sd"
+)
+
+// gatedRegistrar is an sd.Registrar decorator that keeps its delegate registered only while
+// a health check passes.  Register starts checking health periodically, registering and deregistering
+// the delegate as health changes.  Deregister stops the health checks and deregisters the delegate.
+type gatedRegistrar struct {
+	logger   log.Logger
+	delegate sd.Registrar
+	ping     func() error
+	interval time.Duration
+	after    func(time.Duration) <-chan time.Time
+
+	lock sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newGatedRegistrar(o *Options, logger log.Logger, delegate sd.Registrar) *gatedRegistrar {
+	return &gatedRegistrar{
+		logger:   logger,
+		delegate: delegate,
+		ping:     o.pingFunc(),
+		interval: o.pingInterval(),
+		after:    o.after(),
+	}
+}
+
+func (g *gatedRegistrar) Register() {
+	defer g.lock.Unlock()
+	g.lock.Lock()
+
+	if g.stop == nil {
+		g.stop = make(chan struct{})
+		g.done = make(chan struct{})
+		go g.monitor(g.stop, g.done)
+	}
+}
+
+func (g *gatedRegistrar) Deregister() {
+	defer g.lock.Unlock()
+	g.lock.Lock()
+
+	if g.stop != nil {
+		// the lock is held until the monitor exits, so that a subsequent Register
+		// cannot race with the monitor's final deregistration
+		close(g.stop)
+		<-g.done
+		g.stop = nil
+		g.done = nil
+	}
+}
+
+// monitor is the goroutine which checks health and registers or deregisters the delegate
+// whenever health changes.  When stopped, it deregisters the delegate if necessary.
+func (g *gatedRegistrar) monitor(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	registered := false
+
+	for {
+		if err := g.ping(); err == nil {
+			if !registered {
+				g.logger.Log(level.Key(), level.InfoValue(), logging.MessageKey(), "health check passed, registering")
+				g.delegate.Register()
+				registered = true
+			}
+		} else if registered {
+			g.logger.Log(level.Key(), level.ErrorValue(), logging.MessageKey(), "health check failed, deregistering", logging.ErrorKey(), err)
+			g.delegate.Deregister()
+			registered = false
+		}
+
+		select {
+		case <-stop:
+			if registered {
+				g.delegate.Deregister()
+			}
+
+			return
+		case <-g.after(g.interval):
+		}
+	}
+}
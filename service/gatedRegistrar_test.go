@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Comcast/webpa-common/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatedRegistrar(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		delegate = new(mockRegistrar)
+		calls    = make(chan string, 10)
+
+		pingResults = make(chan error)
+		ticks       = make(chan time.Time)
+
+		registrar = newGatedRegistrar(
+			&Options{
+				PingFunc:     func() error { return <-pingResults },
+				PingInterval: 17 * time.Second,
+				After: func(d time.Duration) <-chan time.Time {
+					assert.Equal(17*time.Second, d)
+					return ticks
+				},
+			},
+			logging.NewTestLogger(nil, t),
+			delegate,
+		)
+
+		expectCall = func(expected string) {
+			select {
+			case actual := <-calls:
+				assert.Equal(expected, actual)
+			case <-time.After(5 * time.Second):
+				require.Fail("The delegate was not called", expected)
+			}
+		}
+	)
+
+	delegate.On("Register").Run(func(mock.Arguments) { calls <- "Register" })
+	delegate.On("Deregister").Run(func(mock.Arguments) { calls <- "Deregister" })
+
+	// deregistering before registering does nothing
+	registrar.Deregister()
+
+	registrar.Register()
+	registrar.Register() // idempotent
+	pingResults <- nil
+	expectCall("Register")
+
+	// a passing check while registered does nothing
+	ticks <- time.Now()
+	pingResults <- nil
+
+	ticks <- time.Now()
+	pingResults <- errors.New("expected")
+	expectCall("Deregister")
+
+	// a failing check while deregistered does nothing
+	ticks <- time.Now()
+	pingResults <- errors.New("expected")
+
+	ticks <- time.Now()
+	pingResults <- nil
+	expectCall("Register")
+
+	registrar.Deregister()
+	expectCall("Deregister")
+	registrar.Deregister() // idempotent
+
+	select {
+	case unexpected := <-calls:
+		assert.Fail("Unexpected call to the delegate", unexpected)
+	default:
+	}
+
+	delegate.AssertExpectations(t)
+}
@@ -40,6 +40,18 @@ func (m *mockClient) Stop() {
 	m.Called()
 }
 
+type mockRegistrar struct {
+	mock.Mock
+}
+
+func (m *mockRegistrar) Register() {
+	m.Called()
+}
+
+func (m *mockRegistrar) Deregister() {
+	m.Called()
+}
+
 type mockInstancer struct {
 	mock.Mock
 }
@@ -16,8 +16,15 @@ const (
 	DefaultPath           = "/xmidt"
 	DefaultServiceName    = "test"
 	DefaultVnodeCount     = 211
+	DefaultPingInterval   = 10 * time.Second
 )
 
+// DefaultRegistrationData is the default strategy for building a service's znode data.  It simply
+// uses the original registration string as the data.
+func DefaultRegistrationData(registration Registration) ([]byte, error) {
+	return []byte(registration.Raw), nil
+}
+
 // Options represents the set of configurable attributes for service discovery and registration
 type Options struct {
 	// Logger is used by any component configured via this Options.  If unset, a default
@@ -52,6 +59,16 @@ type Options struct {
 	// Registration is the data stored about this service, typically host:port or scheme://host:port.
 	Registration string `json:"registration,omitempty"`
 
+	// RegistrationMetadata is optional additional information about this service, such as a weight,
+	// that is passed to RegistrationData along with the parsed Registration.
+	RegistrationMetadata map[string]string `json:"registrationMetadata,omitempty"`
+
+	// RegistrationData is the optional function that builds the data stored in this service's znode
+	// from Registration, which is parsed once via ParseRegistration.  Use this to advertise structured data,
+	// such as a JSON object with a scheme, port, and weight, that downstream load balancers can parse.
+	// If not set, DefaultRegistrationData is used, which stores Registration as is.
+	RegistrationData func(Registration) ([]byte, error) `json:"-"`
+
 	// VnodeCount is used to tune the underlying consistent hash algorithm for servers.
 	VnodeCount uint `json:"vnodeCount"`
 
@@ -66,6 +83,14 @@ type Options struct {
 	// After is the optional function to use to obtain a channel which receives a time.Time
 	// after a delay.  If not set, time.After is used.
 	After func(time.Duration) <-chan time.Time `json:"-"`
+
+	// PingFunc is the optional health check for this service.  When set, this service is only
+	// registered while PingFunc returns nil, which keeps traffic away from an instance whose
+	// dependencies are down.  If not set, registration is unconditional.
+	PingFunc func() error `json:"-"`
+
+	// PingInterval is the time between PingFunc checks.  If not set, DefaultPingInterval is used.
+	PingInterval time.Duration `json:"pingInterval"`
 }
 
 func (o *Options) String() string {
@@ -118,6 +143,15 @@ func (o *Options) String() string {
 			output.WriteString(o.UpdateDelay.String())
 		}
 
+		if o.PingInterval > 0 {
+			if output.Len() > 0 {
+				output.WriteString(", ")
+			}
+
+			output.WriteString("pingInterval=")
+			output.WriteString(o.PingInterval.String())
+		}
+
 		if o.VnodeCount > 0 {
 			if output.Len() > 0 {
 				output.WriteString(", ")
@@ -220,6 +254,22 @@ func (o *Options) registration() string {
 	return ""
 }
 
+func (o *Options) registrationMetadata() map[string]string {
+	if o != nil {
+		return o.RegistrationMetadata
+	}
+
+	return nil
+}
+
+func (o *Options) registrationData() func(Registration) ([]byte, error) {
+	if o != nil && o.RegistrationData != nil {
+		return o.RegistrationData
+	}
+
+	return DefaultRegistrationData
+}
+
 func (o *Options) vnodeCount() int {
 	if o != nil && o.VnodeCount > 0 {
 		return int(o.VnodeCount)
@@ -251,3 +301,19 @@ func (o *Options) after() func(time.Duration) <-chan time.Time {
 
 	return time.After
 }
+
+func (o *Options) pingFunc() func() error {
+	if o != nil {
+		return o.PingFunc
+	}
+
+	return nil
+}
+
+func (o *Options) pingInterval() time.Duration {
+	if o != nil && o.PingInterval > 0 {
+		return o.PingInterval
+	}
+
+	return DefaultPingInterval
+}
@@ -22,10 +22,16 @@ func testOptionsDefault(t *testing.T) {
 		assert.Equal(DefaultPath, o.path())
 		assert.Equal(DefaultServiceName, o.serviceName())
 		assert.Empty(o.registration())
+		assert.Nil(o.registrationMetadata())
+		data, err := o.registrationData()(Registration{Raw: "localhost:8080", Host: "localhost", Port: 8080})
+		assert.Equal([]byte("localhost:8080"), data)
+		assert.NoError(err)
 		assert.Equal(DefaultVnodeCount, o.vnodeCount())
 		assert.NotNil(o.instancesFilter())
 		assert.NotNil(o.accessorFactory())
 		assert.NotNil(o.after())
+		assert.Nil(o.pingFunc())
+		assert.Equal(DefaultPingInterval, o.pingInterval())
 		assert.NotEmpty(o.String())
 	}
 }
@@ -44,76 +50,95 @@ func testOptionsCustom(t *testing.T) {
 		customAfterCalled bool
 		customAfter       = func(time.Duration) <-chan time.Time { customAfterCalled = true; return nil }
 
+		customPingCalled bool
+		customPing       = func() error { customPingCalled = true; return nil }
+
+		customRegistrationDataCalled bool
+		customRegistrationData       = func(Registration) ([]byte, error) { customRegistrationDataCalled = true; return nil, nil }
+
 		testData = []struct {
 			options         *Options
 			expectedServers map[string]bool
 		}{
 			{
 				&Options{
-					Logger:          logger,
-					Servers:         []string{"node1.comcast.net:2181", "node2.comcast.net:275"},
-					ConnectTimeout:  16 * time.Minute,
-					SessionTimeout:  2 * time.Hour,
-					UpdateDelay:     3 * time.Minute,
-					Path:            "/testOptions/workspace",
-					ServiceName:     "options",
-					Registration:    "https://comcast.net:8080",
-					VnodeCount:      67912723,
-					InstancesFilter: customInstancesFilter,
-					AccessorFactory: customAccessorFactory,
-					After:           customAfter,
+					Logger:               logger,
+					Servers:              []string{"node1.comcast.net:2181", "node2.comcast.net:275"},
+					ConnectTimeout:       16 * time.Minute,
+					SessionTimeout:       2 * time.Hour,
+					UpdateDelay:          3 * time.Minute,
+					Path:                 "/testOptions/workspace",
+					ServiceName:          "options",
+					Registration:         "https://comcast.net:8080",
+					VnodeCount:           67912723,
+					InstancesFilter:      customInstancesFilter,
+					AccessorFactory:      customAccessorFactory,
+					After:                customAfter,
+					PingFunc:             customPing,
+					PingInterval:         45 * time.Second,
+					RegistrationData:     customRegistrationData,
+					RegistrationMetadata: map[string]string{"weight": "10"},
 				},
 				map[string]bool{"node1.comcast.net:2181": true, "node2.comcast.net:275": true},
 			},
 			{
 				&Options{
-					Logger:          logger,
-					Connection:      "foobar.com:1234",
-					ConnectTimeout:  45 * time.Minute,
-					SessionTimeout:  1 * time.Hour,
-					UpdateDelay:     67 * time.Hour,
-					Path:            "/testOptions/workspace",
-					ServiceName:     "anotherOptions",
-					Registration:    "https://comcast.com:1111",
-					VnodeCount:      398312,
-					InstancesFilter: customInstancesFilter,
-					AccessorFactory: customAccessorFactory,
-					After:           customAfter,
+					Logger:           logger,
+					Connection:       "foobar.com:1234",
+					ConnectTimeout:   45 * time.Minute,
+					SessionTimeout:   1 * time.Hour,
+					UpdateDelay:      67 * time.Hour,
+					Path:             "/testOptions/workspace",
+					ServiceName:      "anotherOptions",
+					Registration:     "https://comcast.com:1111",
+					VnodeCount:       398312,
+					InstancesFilter:  customInstancesFilter,
+					AccessorFactory:  customAccessorFactory,
+					After:            customAfter,
+					PingFunc:         customPing,
+					PingInterval:     45 * time.Second,
+					RegistrationData: customRegistrationData,
 				},
 				map[string]bool{"foobar.com:1234": true},
 			},
 			{
 				&Options{
-					Logger:          logger,
-					Connection:      "grover.net:9999,foobar.com:1234",
-					ConnectTimeout:  123 * time.Second,
-					SessionTimeout:  13 * time.Minute,
-					UpdateDelay:     0,
-					Path:            "/testOptions/anotherone",
-					ServiceName:     "anotherOptions",
-					Registration:    "https://comcast.com:92",
-					VnodeCount:      374,
-					InstancesFilter: customInstancesFilter,
-					AccessorFactory: customAccessorFactory,
-					After:           customAfter,
+					Logger:           logger,
+					Connection:       "grover.net:9999,foobar.com:1234",
+					ConnectTimeout:   123 * time.Second,
+					SessionTimeout:   13 * time.Minute,
+					UpdateDelay:      0,
+					Path:             "/testOptions/anotherone",
+					ServiceName:      "anotherOptions",
+					Registration:     "https://comcast.com:92",
+					VnodeCount:       374,
+					InstancesFilter:  customInstancesFilter,
+					AccessorFactory:  customAccessorFactory,
+					After:            customAfter,
+					PingFunc:         customPing,
+					PingInterval:     45 * time.Second,
+					RegistrationData: customRegistrationData,
 				},
 				map[string]bool{"foobar.com:1234": true, "grover.net:9999": true},
 			},
 			{
 				&Options{
-					Logger:          logger,
-					Connection:      "grover.net:9999,foobar.com:1234",
-					Servers:         []string{"node1.comcast.net:2181", "node2.comcast.net:275"},
-					ConnectTimeout:  3847923 * time.Second,
-					SessionTimeout:  2 * time.Minute,
-					UpdateDelay:     17 * time.Second,
-					Path:            "/testOptions/anotherone",
-					ServiceName:     "anotherOptions",
-					Registration:    "https://comcast.com:92",
-					VnodeCount:      3812,
-					InstancesFilter: customInstancesFilter,
-					AccessorFactory: customAccessorFactory,
-					After:           customAfter,
+					Logger:           logger,
+					Connection:       "grover.net:9999,foobar.com:1234",
+					Servers:          []string{"node1.comcast.net:2181", "node2.comcast.net:275"},
+					ConnectTimeout:   3847923 * time.Second,
+					SessionTimeout:   2 * time.Minute,
+					UpdateDelay:      17 * time.Second,
+					Path:             "/testOptions/anotherone",
+					ServiceName:      "anotherOptions",
+					Registration:     "https://comcast.com:92",
+					VnodeCount:       3812,
+					InstancesFilter:  customInstancesFilter,
+					AccessorFactory:  customAccessorFactory,
+					After:            customAfter,
+					PingFunc:         customPing,
+					PingInterval:     45 * time.Second,
+					RegistrationData: customRegistrationData,
 				},
 				map[string]bool{"node1.comcast.net:2181": true, "node2.comcast.net:275": true, "foobar.com:1234": true, "grover.net:9999": true},
 			},
@@ -137,7 +162,9 @@ func testOptionsCustom(t *testing.T) {
 		assert.Equal(options.Path, options.path())
 		assert.Equal(options.ServiceName, options.serviceName())
 		assert.Equal(options.Registration, options.registration())
+		assert.Equal(options.RegistrationMetadata, options.registrationMetadata())
 		assert.Equal(int(options.VnodeCount), options.vnodeCount())
+		assert.Equal(options.PingInterval, options.pingInterval())
 		assert.NotEmpty(options.String())
 
 		customInstancesFilterCalled = false
@@ -151,6 +178,14 @@ func testOptionsCustom(t *testing.T) {
 		customAfterCalled = false
 		options.after()(time.Minute)
 		assert.True(customAfterCalled)
+
+		customPingCalled = false
+		options.pingFunc()()
+		assert.True(customPingCalled)
+
+		customRegistrationDataCalled = false
+		options.registrationData()(Registration{Raw: options.Registration})
+		assert.True(customRegistrationDataCalled)
 	}
 }
 
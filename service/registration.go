@@ -0,0 +1,68 @@
+package service
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Registration is the structured form of Options.Registration, which is what Options.RegistrationData
+// uses to build a service's znode data.
+type Registration struct {
+	// Raw is the original registration string, e.g. host:port or scheme://host:port
+	Raw string
+
+	// Scheme is the URL scheme of the registration, e.g. https.  This is empty if the
+	// registration did not specify a scheme.
+	Scheme string
+
+	// Host is the hostname or IP address of the registration, without any brackets
+	Host string
+
+	// Port is the port of the registration, or 0 if the registration did not specify a port
+	Port int
+
+	// Metadata is the additional information to advertise about this service, as
+	// configured by Options.RegistrationMetadata.  This map is never nil.
+	Metadata map[string]string
+}
+
+// ParseRegistration parses a registration string of the form host, host:port, scheme://host, or
+// scheme://host:port.  Anything after the host and port, such as a path, is ignored.  An error is
+// returned only if the port is not a valid number.
+//
+// The returned Registration has an empty Metadata map.
+func ParseRegistration(raw string) (Registration, error) {
+	registration := Registration{
+		Raw:      raw,
+		Metadata: make(map[string]string),
+	}
+
+	address := raw
+	if position := strings.Index(address, "://"); position >= 0 {
+		registration.Scheme = address[:position]
+		address = address[position+3:]
+	}
+
+	if position := strings.IndexAny(address, "/?#"); position >= 0 {
+		address = address[:position]
+	}
+
+	// a colon after any closing bracket of an IPv6 literal introduces the port
+	if strings.LastIndex(address, ":") > strings.LastIndex(address, "]") {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return Registration{}, err
+		}
+
+		if registration.Port, err = strconv.Atoi(port); err != nil {
+			return Registration{}, err
+		}
+
+		registration.Host = host
+	} else {
+		registration.Host = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	}
+
+	return registration, nil
+}
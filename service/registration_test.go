@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRegistration(t *testing.T) {
+	testData := []struct {
+		raw      string
+		expected Registration
+	}{
+		{"", Registration{}},
+		{"localhost", Registration{Host: "localhost"}},
+		{"localhost:8080", Registration{Host: "localhost", Port: 8080}},
+		{"https://comcast.net", Registration{Scheme: "https", Host: "comcast.net"}},
+		{"https://comcast.net:8080", Registration{Scheme: "https", Host: "comcast.net", Port: 8080}},
+		{"http://comcast.net:8080/api/v2?x=y", Registration{Scheme: "http", Host: "comcast.net", Port: 8080}},
+		{"[::1]", Registration{Host: "::1"}},
+		{"https://[::1]:1400", Registration{Scheme: "https", Host: "::1", Port: 1400}},
+	}
+
+	for _, record := range testData {
+		t.Run(record.raw, func(t *testing.T) {
+			var (
+				assert   = assert.New(t)
+				expected = record.expected
+			)
+
+			expected.Raw = record.raw
+			expected.Metadata = map[string]string{}
+
+			actual, err := ParseRegistration(record.raw)
+			assert.Equal(expected, actual)
+			assert.NoError(err)
+		})
+	}
+
+	for _, invalid := range []string{"localhost:notaport", "https://comcast.net:-", "host:1:2"} {
+		t.Run(invalid, func(t *testing.T) {
+			assert := assert.New(t)
+
+			actual, err := ParseRegistration(invalid)
+			assert.Equal(Registration{}, actual)
+			assert.Error(err)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"sync/atomic"
 
 	"github.com/Comcast/webpa-common/logging"
@@ -24,6 +25,9 @@ type Interface interface {
 	Close() error
 }
 
+// ErrEmptyRegistrationData is returned by New when Options.RegistrationData produces no data for a registration
+var ErrEmptyRegistrationData = errors.New("The registration data for this service is empty")
+
 // zkFacade is the facade for go-kit/kit/sd/zk
 type zkFacade struct {
 	logger    log.Logger
@@ -73,6 +77,13 @@ var (
 // The returned facade will only be connected to the service discovery backed, e.g. zookeeper.
 // No registration or listening will be active when this function returns.  This allows clients
 // to call Register when the application is truly ready to begin serving requests.
+//
+// If the Options supply a PingFunc, Register begins periodic health checks and the service is only
+// registered while those checks pass.  Deregister and Close stop the health checks.
+//
+// The znode data for a registration is built by Options.RegistrationData from the parsed Registration,
+// including any Options.RegistrationMetadata.  If the Registration cannot be parsed, or if that function
+// fails or produces empty data, the client is stopped and this function returns the error.
 func New(o *Options) (Interface, error) {
 	var (
 		registration = o.registration()
@@ -95,15 +106,30 @@ func New(o *Options) (Interface, error) {
 	}
 
 	if len(registration) > 0 {
+		data, err := registrationData(o, registration)
+		if err == nil && len(data) == 0 {
+			err = ErrEmptyRegistrationData
+		}
+
+		if err != nil {
+			logger.Log(level.Key(), level.ErrorValue(), logging.MessageKey(), "unable to build registration data", logging.ErrorKey(), err)
+			client.Stop()
+			return nil, err
+		}
+
 		registrar = zk.NewRegistrar(
 			client,
 			zk.Service{
 				Path: path,
 				Name: serviceName,
-				Data: []byte(registration),
+				Data: data,
 			},
 			logger,
 		)
+
+		if o.pingFunc() != nil {
+			registrar = newGatedRegistrar(o, logger, registrar)
+		}
 	}
 
 	logger.Log(level.Key(), level.InfoValue(), logging.MessageKey(), "service discovery initialized")
@@ -115,3 +141,17 @@ func New(o *Options) (Interface, error) {
 		registrar: registrar,
 	}, nil
 }
+
+// registrationData parses the given registration and builds its znode data using the configured Options
+func registrationData(o *Options, registration string) ([]byte, error) {
+	parsed, err := ParseRegistration(registration)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range o.registrationMetadata() {
+		parsed.Metadata[key] = value
+	}
+
+	return o.registrationData()(parsed)
+}
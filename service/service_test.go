@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"testing"
+	"time"
 
 	zkclient "github.com/samuel/go-zookeeper/zk"
 
@@ -96,6 +97,127 @@ func testZkFacadeClientFactoryError(t *testing.T) {
 	assert.Equal(expectedError, err)
 }
 
+func testZkFacadeHealthGated(t *testing.T) {
+	defer resetZkClientFactory()
+
+	var (
+		assert     = assert.New(t)
+		require    = require.New(t)
+		client     = new(mockClient)
+		registered = make(chan struct{}, 1)
+
+		o = &Options{
+			Registration: "localhost:1400",
+			PingFunc:     func() error { return nil },
+			After:        func(time.Duration) <-chan time.Time { return nil },
+		}
+	)
+
+	zkClientFactory = func([]string, log.Logger, ...zk.Option) (zk.Client, error) {
+		return client, nil
+	}
+
+	client.On("Register", mock.AnythingOfType("*zk.Service")).Return(error(nil)).Once().
+		Run(func(mock.Arguments) { registered <- struct{}{} })
+	client.On("Deregister", mock.AnythingOfType("*zk.Service")).Return(error(nil)).Once()
+	client.On("Stop").Once()
+
+	service, err := New(o)
+	require.NotNil(service)
+	require.NoError(err)
+
+	// registration happens asynchronously, once the health check passes
+	service.Register()
+	select {
+	case <-registered:
+	case <-time.After(5 * time.Second):
+		require.Fail("The service was not registered")
+	}
+
+	// closing stops the health checks and deregisters the service
+	assert.NoError(service.Close())
+	assert.NoError(service.Close())
+
+	client.AssertExpectations(t)
+}
+
+func testZkFacadeRegistrationData(t *testing.T) {
+	defer resetZkClientFactory()
+
+	var (
+		assert       = assert.New(t)
+		require      = require.New(t)
+		client       = new(mockClient)
+		expectedData = []byte(`{"scheme": "https", "port": 1400, "weight": 10}`)
+
+		o = &Options{
+			Registration:         "https://localhost:1400",
+			RegistrationMetadata: map[string]string{"weight": "10"},
+			RegistrationData: func(registration Registration) ([]byte, error) {
+				assert.Equal(
+					Registration{
+						Raw:      "https://localhost:1400",
+						Scheme:   "https",
+						Host:     "localhost",
+						Port:     1400,
+						Metadata: map[string]string{"weight": "10"},
+					},
+					registration,
+				)
+
+				return expectedData, nil
+			},
+		}
+	)
+
+	zkClientFactory = func([]string, log.Logger, ...zk.Option) (zk.Client, error) {
+		return client, nil
+	}
+
+	client.On("Register", mock.MatchedBy(func(s *zk.Service) bool {
+		return string(expectedData) == string(s.Data)
+	})).Return(error(nil)).Once()
+	client.On("Deregister", mock.MatchedBy(func(s *zk.Service) bool {
+		return string(expectedData) == string(s.Data)
+	})).Return(error(nil)).Once()
+	client.On("Stop").Once()
+
+	service, err := New(o)
+	require.NotNil(service)
+	require.NoError(err)
+
+	service.Register()
+	assert.NoError(service.Close())
+
+	client.AssertExpectations(t)
+}
+
+func testZkFacadeRegistrationDataError(t *testing.T, registration string, data []byte, dataError, expectedError error) {
+	defer resetZkClientFactory()
+
+	var (
+		assert = assert.New(t)
+		client = new(mockClient)
+
+		o = &Options{
+			Registration:     registration,
+			RegistrationData: func(Registration) ([]byte, error) { return data, dataError },
+		}
+	)
+
+	zkClientFactory = func([]string, log.Logger, ...zk.Option) (zk.Client, error) {
+		return client, nil
+	}
+
+	client.On("Stop").Once()
+
+	service, err := New(o)
+	assert.Nil(service)
+	assert.Equal(expectedError, err)
+
+	client.AssertExpectations(t)
+}
+
 func TestZkFacade(t *testing.T) {
 	t.Run("Nil", func(t *testing.T) { testZkFacade(t, nil) })
 	t.Run("Default", func(t *testing.T) { testZkFacade(t, new(Options)) })
@@ -109,4 +231,20 @@ func TestZkFacade(t *testing.T) {
 	})
 
 	t.Run("ClientFactoryError", testZkFacadeClientFactoryError)
+	t.Run("HealthGated", testZkFacadeHealthGated)
+	t.Run("RegistrationData", testZkFacadeRegistrationData)
+	t.Run("RegistrationDataError", func(t *testing.T) {
+		expectedError := errors.New("expected")
+		testZkFacadeRegistrationDataError(t, "localhost:1400", nil, expectedError, expectedError)
+	})
+
+	t.Run("EmptyRegistrationData", func(t *testing.T) {
+		testZkFacadeRegistrationDataError(t, "localhost:1400", []byte{}, nil, ErrEmptyRegistrationData)
+	})
+
+	t.Run("InvalidRegistration", func(t *testing.T) {
+		_, expectedError := ParseRegistration("localhost:notaport")
+		require.Error(t, expectedError)
+		testZkFacadeRegistrationDataError(t, "localhost:notaport", []byte("unused"), nil, expectedError)
+	})
 }
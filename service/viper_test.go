@@ -86,7 +86,8 @@ func testFromViperUnmarshal(t *testing.T) {
 				"path": "/foo/bar",
 				"serviceName": "fantastical",
 				"registration": "https://foobar.com:8080",
-				"vnodeCount": 567829
+				"vnodeCount": 567829,
+				"pingInterval": "30s"
 			}
 		`
 
@@ -108,6 +109,7 @@ func testFromViperUnmarshal(t *testing.T) {
 	assert.Equal("fantastical", o.ServiceName)
 	assert.Equal("https://foobar.com:8080", o.Registration)
 	assert.Equal(uint(567829), o.VnodeCount)
+	assert.Equal(30*time.Second, o.PingInterval)
 }
 
 func TestFromViper(t *testing.T) {
@@ -0,0 +1,110 @@
+package wrp
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+// vectorDirectory is the directory of WRP test vectors used by TestConformance.  To certify
+// against an external set of vectors, run:
+//
+//	go test -run TestConformance github.com/Comcast/webpa-common/wrp -args -wrp.vectors=/path/to/vectors
+var vectorDirectory = flag.String("wrp.vectors", filepath.Join("testdata", "vectors"), "the directory of WRP test vectors")
+
+// canonicalMsgpackHandle is the msgpack configuration used by this package, except that struct fields
+// and map keys are encoded in sorted order.  A normal Encoder writes Metadata entries in no particular
+// order, so its output cannot be compared byte for byte with a vector.
+var canonicalMsgpackHandle = codec.MsgpackHandle{
+	WriteExt:    true,
+	RawToString: true,
+	BasicHandle: codec.BasicHandle{
+		TypeInfos:     codec.NewTypeInfos([]string{"wrp"}),
+		EncodeOptions: codec.EncodeOptions{Canonical: true},
+	},
+}
+
+// canonicalMsgpack encodes the given message using canonicalMsgpackHandle
+func canonicalMsgpack(t *testing.T, message *Message) []byte {
+	var output []byte
+	require.NoError(t, codec.NewEncoderBytes(&output, &canonicalMsgpackHandle).Encode(message))
+	return output
+}
+
+// vector is a single canonical WRP message, in both msgpack and JSON form.  The msgpack form
+// must be a canonical encoding, with struct fields and map keys in sorted order.
+type vector struct {
+	name    string
+	msgpack []byte
+	json    []byte
+}
+
+// loadVectors reads all the test vectors in a directory.  Each vector is a pair of files
+// with the same base name:  a binary .msgpack file and a companion .json file.
+func loadVectors(t *testing.T, directory string) []vector {
+	msgpackFiles, err := filepath.Glob(filepath.Join(directory, "*.msgpack"))
+	require.NoError(t, err)
+	require.NotEmpty(t, msgpackFiles, "No test vectors found in %s", directory)
+
+	vectors := make([]vector, 0, len(msgpackFiles))
+	for _, msgpackFile := range msgpackFiles {
+		var (
+			name     = strings.TrimSuffix(filepath.Base(msgpackFile), ".msgpack")
+			jsonFile = strings.TrimSuffix(msgpackFile, ".msgpack") + ".json"
+			v        = vector{name: name}
+		)
+
+		v.msgpack, err = ioutil.ReadFile(msgpackFile)
+		require.NoError(t, err)
+
+		v.json, err = ioutil.ReadFile(jsonFile)
+		require.NoError(t, err, "Missing JSON companion for vector %s", name)
+
+		vectors = append(vectors, v)
+	}
+
+	return vectors
+}
+
+func testConformanceVector(t *testing.T, v vector) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		fromMsgpack Message
+		fromJSON    Message
+	)
+
+	require.NoError(NewDecoderBytes(v.msgpack, Msgpack).Decode(&fromMsgpack))
+	require.NoError(NewDecoderBytes(v.json, JSON).Decode(&fromJSON))
+	assert.Equal(fromJSON, fromMsgpack, "The msgpack and JSON forms decode to different messages")
+
+	var transcodedJSON []byte
+	require.NoError(NewEncoderBytes(&transcodedJSON, JSON).Encode(&fromMsgpack))
+	assert.JSONEq(string(v.json), string(transcodedJSON), "msgpack to JSON transcoding does not match the expected JSON")
+
+	var (
+		transcodedMsgpack []byte
+		fromTranscoded    Message
+	)
+
+	require.NoError(NewEncoderBytes(&transcodedMsgpack, Msgpack).Encode(&fromJSON))
+	require.NoError(NewDecoderBytes(transcodedMsgpack, Msgpack).Decode(&fromTranscoded))
+	assert.Equal(fromMsgpack, fromTranscoded, "JSON to msgpack transcoding does not decode to the expected message")
+	assert.Equal(v.msgpack, canonicalMsgpack(t, &fromJSON), "JSON to msgpack transcoding does not match the expected msgpack")
+}
+
+func TestConformance(t *testing.T) {
+	for _, v := range loadVectors(t, *vectorDirectory) {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			testConformanceVector(t, v)
+		})
+	}
+}
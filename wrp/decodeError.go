@@ -0,0 +1,135 @@
+package wrp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/ugorji/go/codec"
+)
+
+// DecodeError is returned by Decoders when input cannot be decoded.  It reports how far into the input
+// decoding got before the failure, which helps locate problems with a bad producer.
+//
+// A Decoder reading an io.Reader cannot resume after a DecodeError, since nothing delimits the bad value.
+// Streams which must survive bad messages should use a delimited layout, read by JSONLinesDecoder or FrameReader.
+type DecodeError struct {
+	// Format is the format being decoded
+	Format Format
+
+	// Offset is the number of bytes of input consumed when the error was detected.  For a Decoder reading
+	// an io.Reader, this is measured from the start of the stream.  For a Decoder reading a byte slice, this
+	// is measured from the start of the slice.  The codec may read slightly ahead, so the problem lies at or
+	// shortly before this offset.
+	Offset int64
+
+	// Err is the underlying error from the codec.  Where the codec can tell, this error describes the
+	// field or token being decoded.
+	Err error
+}
+
+func (de *DecodeError) Error() string {
+	return fmt.Sprintf("%s decode error at offset %d: %s", de.Format, de.Offset, de.Err)
+}
+
+// countingReader tracks the number of bytes read from a delegate io.Reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.count += int64(n)
+	return n, err
+}
+
+// decoderDecorator wraps a ugorji Decoder and implements the wrp.Decoder interface.  Errors,
+// other than io.EOF, are reported as *DecodeError.
+type decoderDecorator struct {
+	*codec.Decoder
+	format Format
+
+	// stream counts the bytes read when decoding from an io.Reader, which is indicated by streaming.
+	// It is held by value so that Reset does not allocate.
+	stream    countingReader
+	streaming bool
+
+	// input is the byte slice being decoded, and decoded is the number of values successfully decoded
+	// from it.  These are only used to compute the offset of an error, which is done by replaying the decode.
+	// This keeps the common, successful path for bytes as fast as the undecorated codec.
+	input   []byte
+	decoded int
+}
+
+func newDecoderDecorator(f Format) *decoderDecorator {
+	return &decoderDecorator{
+		Decoder: codec.NewDecoderBytes(nil, f.handle()),
+		format:  f,
+	}
+}
+
+func (dd *decoderDecorator) Reset(input io.Reader) {
+	dd.stream = countingReader{reader: input}
+	dd.streaming = true
+	dd.input = nil
+	dd.decoded = 0
+	dd.Decoder.Reset(&dd.stream)
+}
+
+func (dd *decoderDecorator) ResetBytes(input []byte) {
+	dd.stream = countingReader{}
+	dd.streaming = false
+	dd.input = input
+	dd.decoded = 0
+	dd.Decoder.ResetBytes(input)
+}
+
+func (dd *decoderDecorator) Decode(value interface{}) error {
+	err := dd.Decoder.Decode(value)
+	if err == nil {
+		dd.decoded++
+		return nil
+	} else if err == io.EOF {
+		return err
+	}
+
+	decodeError := &DecodeError{Format: dd.format, Err: err}
+	if dd.streaming {
+		decodeError.Offset = dd.stream.count
+	} else {
+		decodeError.Offset = dd.bytesOffset(value)
+	}
+
+	return decodeError
+}
+
+// bytesOffset replays decoding of the current byte slice through a counting reader in order to determine
+// how far into the slice the most recent decode failed.
+func (dd *decoderDecorator) bytesOffset(value interface{}) int64 {
+	var (
+		counter = &countingReader{reader: bytes.NewReader(dd.input)}
+		replay  = codec.NewDecoder(counter, dd.format.handle())
+	)
+
+	for i := 0; i < dd.decoded; i++ {
+		var skip interface{}
+		if replay.Decode(&skip) != nil {
+			return counter.count
+		}
+	}
+
+	start := counter.count
+	valueType := reflect.TypeOf(value)
+	if valueType == nil || valueType.Kind() != reflect.Ptr {
+		return start
+	}
+
+	if replay.Decode(reflect.New(valueType.Elem()).Interface()) == nil {
+		// the replay unexpectedly succeeded, so the best available answer is the start of the value
+		return start
+	}
+
+	return counter.count
+}
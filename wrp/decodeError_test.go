@@ -0,0 +1,112 @@
+package wrp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// corruptMsgpack produces a stream of (3) msgpack messages, where the second message
+// has its source string truncated so that its map cannot be decoded.  The offset of the
+// corruption within the stream is returned as well.
+func corruptMsgpack(t *testing.T) ([]byte, int) {
+	var (
+		first, second, third []byte
+		message              = &Message{Type: SimpleEventMessageType, Source: "source", Destination: "destination"}
+	)
+
+	require.NoError(t, NewEncoderBytes(&first, Msgpack).Encode(message))
+	require.NoError(t, NewEncoderBytes(&second, Msgpack).Encode(message))
+	require.NoError(t, NewEncoderBytes(&third, Msgpack).Encode(message))
+
+	// replace the source string header, a fixstr, with the reserved msgpack code 0xc1
+	position := bytes.Index(second, []byte("source")) + len("source")
+	require.Equal(t, byte(0xa6), second[position])
+	second[position] = 0xc1
+
+	stream := append(append(first, second...), third...)
+	return stream, len(first) + position
+}
+
+func testDecodeErrorStream(t *testing.T) {
+	var (
+		assert            = assert.New(t)
+		stream, corrupted = corruptMsgpack(t)
+		decoder           = NewDecoder(bytes.NewReader(stream), Msgpack)
+	)
+
+	assert.NoError(decoder.Decode(new(Message)))
+
+	err := decoder.Decode(new(Message))
+	if decodeError, ok := err.(*DecodeError); assert.True(ok, "expected a *DecodeError, got %T", err) {
+		assert.Equal(Msgpack, decodeError.Format)
+		assert.True(decodeError.Offset > int64(corrupted), "offset %d is before the corruption at %d", decodeError.Offset, corrupted)
+		assert.True(decodeError.Offset <= int64(corrupted+2), "offset %d is too far past the corruption at %d", decodeError.Offset, corrupted)
+		assert.Contains(decodeError.Error(), "Msgpack decode error at offset")
+	}
+}
+
+func testDecodeErrorBytes(t *testing.T) {
+	var (
+		assert            = assert.New(t)
+		stream, corrupted = corruptMsgpack(t)
+		decoder           = NewDecoderBytes(stream, Msgpack)
+	)
+
+	assert.NoError(decoder.Decode(new(Message)))
+
+	err := decoder.Decode(new(Message))
+	if decodeError, ok := err.(*DecodeError); assert.True(ok, "expected a *DecodeError, got %T", err) {
+		assert.Equal(Msgpack, decodeError.Format)
+		assert.True(decodeError.Offset > int64(corrupted), "offset %d is before the corruption at %d", decodeError.Offset, corrupted)
+		assert.True(decodeError.Offset <= int64(corrupted+2), "offset %d is too far past the corruption at %d", decodeError.Offset, corrupted)
+	}
+
+	// offsets are relative to the most recent ResetBytes, so input starting at the corruption fails immediately
+	decoder.ResetBytes(stream[corrupted:])
+	err = decoder.Decode(new(Message))
+	if decodeError, ok := err.(*DecodeError); assert.True(ok, "expected a *DecodeError, got %T", err) {
+		assert.True(decodeError.Offset <= 1, "offset %d is not relative to the reset input", decodeError.Offset)
+	}
+}
+
+func testDecodeErrorJSON(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		input  = []byte(`{"msg_type": 4, "source": "x" zz}`)
+	)
+
+	err := NewDecoderBytes(input, JSON).Decode(new(Message))
+	if decodeError, ok := err.(*DecodeError); assert.True(ok, "expected a *DecodeError, got %T", err) {
+		assert.Equal(JSON, decodeError.Format)
+		assert.Equal(int64(bytes.IndexByte(input, 'z')+1), decodeError.Offset)
+	}
+}
+
+func testDecodeErrorEOF(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(io.EOF, NewDecoder(new(bytes.Buffer), Msgpack).Decode(new(Message)))
+	assert.Equal(io.EOF, NewDecoderBytes([]byte{}, Msgpack).Decode(new(Message)))
+}
+
+func testDecodeErrorResetAllocs(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		decoder = NewDecoder(nil, Msgpack)
+		input   = bytes.NewReader(nil)
+	)
+
+	// tracking offsets must not cost an allocation each time a decoder is reused
+	assert.Zero(testing.AllocsPerRun(100, func() { decoder.Reset(input) }))
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Run("Stream", testDecodeErrorStream)
+	t.Run("Bytes", testDecodeErrorBytes)
+	t.Run("JSON", testDecodeErrorJSON)
+	t.Run("EOF", testDecodeErrorEOF)
+	t.Run("ResetAllocs", testDecodeErrorResetAllocs)
+}
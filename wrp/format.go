@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"mime"
 	"strings"
 
 	"github.com/ugorji/go/codec"
@@ -28,9 +29,11 @@ func AllFormats() []Format {
 }
 
 var (
+	// jsonHandle encodes map keys in sorted order, so that the JSON form of a message is reproducible
 	jsonHandle = codec.JsonHandle{
 		BasicHandle: codec.BasicHandle{
-			TypeInfos: codec.NewTypeInfos([]string{"wrp"}),
+			TypeInfos:     codec.NewTypeInfos([]string{"wrp"}),
+			EncodeOptions: codec.EncodeOptions{Canonical: true},
 		},
 		IntegerAsString: 'L',
 	}
@@ -59,17 +62,37 @@ func (f Format) ContentType() string {
 	}
 }
 
+// contentTypeFormats maps the media types understood by FormatFromContentType onto formats
+var contentTypeFormats = map[string]Format{
+	"application/msgpack":     Msgpack,
+	"application/x-msgpack":   Msgpack,
+	"application/vnd.msgpack": Msgpack,
+	"application/json":        JSON,
+	"text/json":               JSON,
+}
+
 // FormatFromContentType examines the Content-Type value and returns
-// the appropriate Format.  This function returns an error if the given
-// Content-Type did not map to a WRP format.
+// the appropriate Format.  Media type parameters, such as charset, are ignored.
+// In addition to the standard msgpack and JSON media types, any structured syntax
+// type ending in +msgpack or +json, e.g. application/vnd.wrp+json, is recognized.
+//
+// This function returns an error if the given Content-Type did not map to a WRP format,
+// which HTTP handlers will typically report as a 415 (Unsupported Media Type).
 func FormatFromContentType(contentType string) (Format, error) {
-	if strings.Contains(contentType, "json") {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Format(-1), fmt.Errorf("Invalid WRP content type %q: %s", contentType, err)
+	}
+
+	if f, ok := contentTypeFormats[mediaType]; ok {
+		return f, nil
+	} else if strings.HasSuffix(mediaType, "+json") {
 		return JSON, nil
-	} else if strings.Contains(contentType, "msgpack") {
+	} else if strings.HasSuffix(mediaType, "+msgpack") {
 		return Msgpack, nil
 	}
 
-	return Format(-1), fmt.Errorf("Invalid WRP content type: %s", contentType)
+	return Format(-1), fmt.Errorf("Unsupported WRP content type: %s", contentType)
 }
 
 // handle looks up the appropriate codec.Handle for this format constant.
@@ -92,7 +115,12 @@ type EncodeListener interface {
 	BeforeEncode() error
 }
 
-// Encoder represents the underlying ugorji behavior that WRP supports
+// Encoder represents the underlying ugorji behavior that WRP supports.
+//
+// An Encoder is reusable.  Reset and ResetBytes rebind it to a new output without reallocating
+// the underlying codec state, so a single goroutine encoding sequentially to many outputs can
+// keep one Encoder rather than using an EncoderPool, whose locking is unnecessary in that case.
+// An Encoder is not safe for concurrent use.
 type Encoder interface {
 	Encode(interface{}) error
 	Reset(io.Writer)
@@ -117,7 +145,11 @@ func (ed *encoderDecorator) Encode(value interface{}) error {
 	return ed.Encoder.Encode(value)
 }
 
-// Decoder represents the underlying ugorji behavior that WRP supports
+// Decoder represents the underlying ugorji behavior that WRP supports.
+//
+// Like an Encoder, a Decoder is reusable.  Reset and ResetBytes rebind it to a new input without
+// reallocating the underlying codec state or the state used to report a *DecodeError's offset.
+// A Decoder is not safe for concurrent use.
 type Decoder interface {
 	Decode(interface{}) error
 	Reset(io.Reader)
@@ -141,15 +173,19 @@ func NewEncoderBytes(output *[]byte, f Format) Encoder {
 }
 
 // NewDecoder produces a ugorji Decoder using the appropriate WRP configuration
-// for the given format
+// for the given format.  Decode errors, other than io.EOF, are reported as *DecodeError.
 func NewDecoder(input io.Reader, f Format) Decoder {
-	return codec.NewDecoder(input, f.handle())
+	decoder := newDecoderDecorator(f)
+	decoder.Reset(input)
+	return decoder
 }
 
 // NewDecoderBytes produces a ugorji Decoder using the appropriate WRP configuration
-// for the given format
+// for the given format.  Decode errors, other than io.EOF, are reported as *DecodeError.
 func NewDecoderBytes(input []byte, f Format) Decoder {
-	return codec.NewDecoderBytes(input, f.handle())
+	decoder := newDecoderDecorator(f)
+	decoder.ResetBytes(input)
+	return decoder
 }
 
 // TranscodeMessage converts a WRP message of any type from one format into another,
@@ -165,6 +165,12 @@ func testFormatContentType(t *testing.T) {
 	assert.NotEmpty(Msgpack.ContentType())
 	assert.NotEqual(JSON.ContentType(), Msgpack.ContentType())
 	assert.Equal("application/octet-stream", Format(999).ContentType())
+
+	for _, f := range AllFormats() {
+		actual, err := FormatFromContentType(f.ContentType())
+		assert.Equal(f, actual)
+		assert.NoError(err)
+	}
 }
 
 func testFormatFromContentType(t *testing.T) {
@@ -177,8 +183,18 @@ func testFormatFromContentType(t *testing.T) {
 		}{
 			{"application/json", JSON, false},
 			{"application/json;charset=utf-8", JSON, false},
+			{"Application/JSON; charset=UTF-8", JSON, false},
+			{"text/json", JSON, false},
+			{"application/vnd.wrp+json", JSON, false},
 			{"application/msgpack", Msgpack, false},
+			{"application/x-msgpack", Msgpack, false},
+			{"application/vnd.msgpack", Msgpack, false},
+			{"application/vnd.wrp+msgpack", Msgpack, false},
 			{"text/plain", Format(-1), true},
+			{"application/jsonish", Format(-1), true},
+			{"application/octet-stream", Format(-1), true},
+			{"", Format(-1), true},
+			{"application/json; charset", Format(-1), true},
 		}
 	)
 
@@ -282,6 +298,7 @@ func TestTranscodeMessage(t *testing.T) {
 	var (
 		expectedStatus                  int64 = 123
 		expectedRequestDeliveryResponse int64 = -1234
+		expectedQualityOfService        int64 = 24
 
 		messages = []interface{}{
 			AuthorizationStatus{},
@@ -301,6 +318,8 @@ func TestTranscodeMessage(t *testing.T) {
 				Accept:                  "application/wrp",
 				Status:                  &expectedStatus,
 				RequestDeliveryResponse: &expectedRequestDeliveryResponse,
+				QualityOfService:        &expectedQualityOfService,
+				PartnerIDs:              []string{"comcast", "partner"},
 				Headers:                 []string{"X-Header-1", "X-Header-2"},
 				Metadata:                map[string]string{"hi": "there"},
 				Payload:                 []byte("hi!"),
@@ -318,6 +337,8 @@ func TestTranscodeMessage(t *testing.T) {
 				Accept:                  "application/wrp",
 				Status:                  &expectedStatus,
 				RequestDeliveryResponse: &expectedRequestDeliveryResponse,
+				QualityOfService:        &expectedQualityOfService,
+				PartnerIDs:              []string{"comcast", "partner"},
 				Headers:                 []string{"X-Header-1", "X-Header-2"},
 				Metadata:                map[string]string{"hi": "there"},
 				Payload:                 []byte("hi!"),
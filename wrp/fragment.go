@@ -0,0 +1,255 @@
+package wrp
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// FragmentIndexKey is the metadata key holding the zero-based position of a fragment
+	FragmentIndexKey = "wrp-fragment-index"
+
+	// FragmentCountKey is the metadata key holding the total number of fragments for a message
+	FragmentCountKey = "wrp-fragment-count"
+
+	// minBinHeaderSize and maxBinHeaderSize are the sizes of the smallest and largest
+	// msgpack headers for a bin value, i.e. bin8 and bin32
+	minBinHeaderSize = 2
+	maxBinHeaderSize = 5
+
+	// DefaultMaxFragments is the largest fragment count a Reassembler accepts when none is configured
+	DefaultMaxFragments = 1024
+
+	// DefaultMaxPending is the largest number of incomplete messages a Reassembler holds when none is configured
+	DefaultMaxPending = 1024
+)
+
+var (
+	ErrFragmentTransactionRequired = errors.New("A transaction_uuid is required to fragment a message")
+	ErrFrameTooSmall               = errors.New("The maximum frame size cannot hold any payload")
+	ErrInvalidFragment             = errors.New("Invalid fragment metadata")
+	ErrTooManyPending              = errors.New("Too many messages with fragments outstanding")
+)
+
+// FragmentMessage encodes a message as one or more msgpack frames, none of which are larger than maxFrame
+// bytes.  If the encoded message already fits in a single frame, it is returned as is.  Otherwise, the payload
+// is split across frames, each of which is a copy of the message carrying a piece of the payload and
+// FragmentIndexKey and FragmentCountKey metadata.  Fragments are correlated by the message's TransactionUUID,
+// so oversized messages without one cannot be fragmented.
+//
+// Use a Reassembler to recover the original message from the frames.
+func FragmentMessage(msg *Message, maxFrame int) ([][]byte, error) {
+	var whole []byte
+	if err := NewEncoderBytes(&whole, Msgpack).Encode(msg); err != nil {
+		return nil, err
+	} else if len(whole) <= maxFrame {
+		return [][]byte{whole}, nil
+	} else if len(msg.TransactionUUID) == 0 {
+		return nil, ErrFragmentTransactionRequired
+	}
+
+	// the largest possible fragment, minus its payload, determines how much payload each frame can hold.
+	// a single byte payload ensures the payload field is encoded, with a bin8 header that may need to grow.
+	var (
+		largestCount = strconv.Itoa(len(msg.Payload))
+		header       []byte
+	)
+
+	if err := NewEncoderBytes(&header, Msgpack).Encode(newFragment(msg, []byte{0}, largestCount, largestCount)); err != nil {
+		return nil, err
+	}
+
+	chunkSize := maxFrame - (len(header) - 1) - (maxBinHeaderSize - minBinHeaderSize)
+	if chunkSize < 1 {
+		return nil, ErrFrameTooSmall
+	}
+
+	var (
+		count  = (len(msg.Payload) + chunkSize - 1) / chunkSize
+		frames = make([][]byte, 0, count)
+	)
+
+	for index := 0; index < count; index++ {
+		end := (index + 1) * chunkSize
+		if end > len(msg.Payload) {
+			end = len(msg.Payload)
+		}
+
+		var frame []byte
+		fragment := newFragment(msg, msg.Payload[index*chunkSize:end], strconv.Itoa(index), strconv.Itoa(count))
+		if err := NewEncoderBytes(&frame, Msgpack).Encode(fragment); err != nil {
+			return nil, err
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// newFragment produces a shallow copy of a message with the given piece of the payload and fragment metadata
+func newFragment(msg *Message, payload []byte, index, count string) *Message {
+	fragment := *msg
+	fragment.Payload = payload
+	fragment.Metadata = make(map[string]string, len(msg.Metadata)+2)
+	for key, value := range msg.Metadata {
+		fragment.Metadata[key] = value
+	}
+
+	fragment.Metadata[FragmentIndexKey] = index
+	fragment.Metadata[FragmentCountKey] = count
+	return &fragment
+}
+
+// fragmentSet holds the fragments received so far for a single transaction
+type fragmentSet struct {
+	started  time.Time
+	received int
+	messages []*Message
+}
+
+// Reassembler rebuilds messages split apart by FragmentMessage.  Fragments may arrive in any order.
+// Incomplete sets of fragments are discarded once they are older than the Reassembler's timeout.
+//
+// Fragment metadata comes from the sender and is not trusted.  A Reassembler bounds both the fragment
+// count of any one message and the number of messages with fragments outstanding.
+//
+// A Reassembler is safe for concurrent use.
+type Reassembler struct {
+	lock         sync.Mutex
+	timeout      time.Duration
+	maxFragments int
+	maxPending   int
+	now          func() time.Time
+	pending      map[string]*fragmentSet
+}
+
+// NewReassembler creates a Reassembler which waits at most the given timeout for all the
+// fragments of a message to arrive.  Fragments which claim a count larger than maxFragments are
+// rejected with ErrInvalidFragment, and fragments which would start more than maxPending incomplete
+// messages are rejected with ErrTooManyPending.  Nonpositive values for maxFragments and maxPending
+// select DefaultMaxFragments and DefaultMaxPending, respectively.
+func NewReassembler(timeout time.Duration, maxFragments, maxPending int) *Reassembler {
+	if maxFragments < 1 {
+		maxFragments = DefaultMaxFragments
+	}
+
+	if maxPending < 1 {
+		maxPending = DefaultMaxPending
+	}
+
+	return &Reassembler{
+		timeout:      timeout,
+		maxFragments: maxFragments,
+		maxPending:   maxPending,
+		now:          time.Now,
+		pending:      make(map[string]*fragmentSet),
+	}
+}
+
+// Add decodes a msgpack frame.  If the frame is not a fragment, its message is returned immediately.
+// If the frame is the last missing fragment of a message, the reassembled message is returned.  Otherwise,
+// the fragment is held and this method returns a nil message and a nil error.
+func (r *Reassembler) Add(frame []byte) (*Message, error) {
+	msg := new(Message)
+	if err := NewDecoderBytes(frame, Msgpack).Decode(msg); err != nil {
+		return nil, err
+	}
+
+	indexValue, hasIndex := msg.Metadata[FragmentIndexKey]
+	countValue, hasCount := msg.Metadata[FragmentCountKey]
+	if !hasIndex && !hasCount {
+		return msg, nil
+	}
+
+	index, indexErr := strconv.Atoi(indexValue)
+	count, countErr := strconv.Atoi(countValue)
+	if indexErr != nil || countErr != nil || count < 1 || count > r.maxFragments || index < 0 || index >= count || len(msg.TransactionUUID) == 0 {
+		return nil, ErrInvalidFragment
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	r.expire(now)
+
+	set, ok := r.pending[msg.TransactionUUID]
+	if !ok {
+		if len(r.pending) >= r.maxPending {
+			return nil, ErrTooManyPending
+		}
+
+		set = &fragmentSet{started: now, messages: make([]*Message, count)}
+		r.pending[msg.TransactionUUID] = set
+	} else if len(set.messages) != count {
+		delete(r.pending, msg.TransactionUUID)
+		return nil, ErrInvalidFragment
+	}
+
+	if set.messages[index] == nil {
+		set.received++
+	}
+
+	set.messages[index] = msg
+	if set.received < count {
+		return nil, nil
+	}
+
+	delete(r.pending, msg.TransactionUUID)
+	return reassemble(set.messages), nil
+}
+
+// Expire discards any incomplete sets of fragments that have exceeded the timeout, returning
+// the transaction identifiers of the discarded sets.  Expiration also happens as fragments are added,
+// so calling this method is only necessary to free memory when fragments stop arriving.
+func (r *Reassembler) Expire() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.expire(r.now())
+}
+
+// Len returns the number of messages with fragments still outstanding
+func (r *Reassembler) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.pending)
+}
+
+func (r *Reassembler) expire(now time.Time) (expired []string) {
+	for transactionUUID, set := range r.pending {
+		if now.Sub(set.started) >= r.timeout {
+			delete(r.pending, transactionUUID)
+			expired = append(expired, transactionUUID)
+		}
+	}
+
+	return
+}
+
+// reassemble joins a complete set of fragments into a single message
+func reassemble(fragments []*Message) *Message {
+	var (
+		msg  = *fragments[0]
+		size int
+	)
+
+	for _, fragment := range fragments {
+		size += len(fragment.Payload)
+	}
+
+	msg.Payload = make([]byte, 0, size)
+	for _, fragment := range fragments {
+		msg.Payload = append(msg.Payload, fragment.Payload...)
+	}
+
+	delete(msg.Metadata, FragmentIndexKey)
+	delete(msg.Metadata, FragmentCountKey)
+	if len(msg.Metadata) == 0 {
+		msg.Metadata = nil
+	}
+
+	return &msg
+}
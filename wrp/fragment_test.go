@@ -0,0 +1,201 @@
+package wrp
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFragmentTestMessage(payloadSize int) *Message {
+	payload := make([]byte, payloadSize)
+	rand.Read(payload)
+
+	return &Message{
+		Type:            SimpleRequestResponseMessageType,
+		Source:          "dns:talaria.comcast.net",
+		Destination:     "mac:112233445566/firmware",
+		TransactionUUID: "fragment-test",
+		ContentType:     "application/octet-stream",
+		Metadata:        map[string]string{"firmware": "1.2.3"},
+		Payload:         payload,
+	}
+}
+
+func TestFragmentMessageSingleFrame(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		msg     = newFragmentTestMessage(100)
+	)
+
+	frames, err := FragmentMessage(msg, 1024)
+	require.NoError(err)
+	require.Len(frames, 1)
+	assert.Equal(MustEncode(msg, Msgpack), frames[0])
+
+	actual, err := NewReassembler(time.Minute, 0, 0).Add(frames[0])
+	assert.NoError(err)
+	assert.Equal(msg, actual)
+}
+
+func TestFragmentMessage(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		msg         = newFragmentTestMessage(3000)
+		maxFrame    = 1400
+		reassembler = NewReassembler(time.Minute, 0, 0)
+	)
+
+	frames, err := FragmentMessage(msg, maxFrame)
+	require.NoError(err)
+	require.Len(frames, 3)
+
+	for _, frame := range frames {
+		assert.True(len(frame) <= maxFrame)
+	}
+
+	// fragments can arrive in any order
+	for _, i := range []int{2, 0} {
+		actual, err := reassembler.Add(frames[i])
+		assert.Nil(actual)
+		assert.NoError(err)
+	}
+
+	assert.Equal(1, reassembler.Len())
+	actual, err := reassembler.Add(frames[1])
+	require.NoError(err)
+	assert.Equal(msg, actual)
+	assert.Zero(reassembler.Len())
+}
+
+func TestFragmentMessageErrors(t *testing.T) {
+	t.Run("NoTransaction", func(t *testing.T) {
+		msg := newFragmentTestMessage(3000)
+		msg.TransactionUUID = ""
+
+		frames, err := FragmentMessage(msg, 1400)
+		assert.Empty(t, frames)
+		assert.Equal(t, ErrFragmentTransactionRequired, err)
+	})
+
+	t.Run("FrameTooSmall", func(t *testing.T) {
+		frames, err := FragmentMessage(newFragmentTestMessage(3000), 50)
+		assert.Empty(t, frames)
+		assert.Equal(t, ErrFrameTooSmall, err)
+	})
+}
+
+func TestReassemblerTimeout(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		msg         = newFragmentTestMessage(3000)
+		now         = time.Now()
+		reassembler = NewReassembler(time.Second, 0, 0)
+	)
+
+	reassembler.now = func() time.Time { return now }
+	frames, err := FragmentMessage(msg, 1400)
+	require.NoError(err)
+	require.Len(frames, 3)
+
+	// the middle fragment never arrives
+	for _, i := range []int{0, 2} {
+		actual, err := reassembler.Add(frames[i])
+		assert.Nil(actual)
+		assert.NoError(err)
+	}
+
+	assert.Empty(reassembler.Expire())
+	assert.Equal(1, reassembler.Len())
+
+	now = now.Add(time.Second)
+	assert.Equal([]string{msg.TransactionUUID}, reassembler.Expire())
+	assert.Zero(reassembler.Len())
+
+	// a late fragment starts a new set rather than completing the expired one
+	actual, err := reassembler.Add(frames[1])
+	assert.Nil(actual)
+	assert.NoError(err)
+	assert.Equal(1, reassembler.Len())
+}
+
+func TestReassemblerInvalidFragment(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, metadata := range []map[string]string{
+		{FragmentIndexKey: "0"},
+		{FragmentIndexKey: "x", FragmentCountKey: "2"},
+		{FragmentIndexKey: "2", FragmentCountKey: "2"},
+		{FragmentIndexKey: "-1", FragmentCountKey: "2"},
+		{FragmentIndexKey: "0", FragmentCountKey: strconv.Itoa(DefaultMaxFragments + 1)},
+		{FragmentIndexKey: "0", FragmentCountKey: "9223372036854775807"},
+	} {
+		frame := MustEncode(&Message{Type: SimpleEventMessageType, TransactionUUID: "1", Metadata: metadata}, Msgpack)
+		actual, err := NewReassembler(time.Minute, 0, 0).Add(frame)
+		assert.Nil(actual)
+		assert.Equal(ErrInvalidFragment, err)
+	}
+
+	actual, err := NewReassembler(time.Minute, 0, 0).Add([]byte("this is not msgpack"))
+	assert.Nil(actual)
+	assert.Error(err)
+}
+
+func TestReassemblerMaxFragments(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		msg         = newFragmentTestMessage(3000)
+		reassembler = NewReassembler(time.Minute, 2, 0)
+	)
+
+	frames, err := FragmentMessage(msg, 1400)
+	require.NoError(err)
+	require.Len(frames, 3)
+
+	actual, err := reassembler.Add(frames[0])
+	assert.Nil(actual)
+	assert.Equal(ErrInvalidFragment, err)
+	assert.Zero(reassembler.Len())
+}
+
+func TestReassemblerMaxPending(t *testing.T) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		reassembler = NewReassembler(time.Minute, 0, 1)
+
+		first  = newFragmentTestMessage(3000)
+		second = newFragmentTestMessage(3000)
+	)
+
+	second.TransactionUUID = "second"
+	firstFrames, err := FragmentMessage(first, 1400)
+	require.NoError(err)
+	secondFrames, err := FragmentMessage(second, 1400)
+	require.NoError(err)
+
+	actual, err := reassembler.Add(firstFrames[0])
+	assert.Nil(actual)
+	assert.NoError(err)
+
+	actual, err = reassembler.Add(secondFrames[0])
+	assert.Nil(actual)
+	assert.Equal(ErrTooManyPending, err)
+	assert.Equal(1, reassembler.Len())
+
+	// fragments of a message already pending are still accepted
+	for _, frame := range firstFrames[1:] {
+		actual, err = reassembler.Add(frame)
+		assert.NoError(err)
+	}
+
+	assert.Equal(first, actual)
+	assert.Zero(reassembler.Len())
+}
@@ -0,0 +1,107 @@
+package wrp
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrShortFrame indicates that a stream of length-prefixed frames ended partway through a frame,
+// either within the length prefix or within the encoded message that follows it
+var ErrShortFrame = errors.New("The stream ended partway through a frame")
+
+// FrameWriter writes WRP messages to a self-delimiting stream, with each message in its own length-prefixed
+// frame as written by WriteFrame.  This is useful for persisting a capture of messages that were originally
+// sent as discrete websocket frames.  A FrameWriter reuses its Encoder and encoding buffer across messages.
+//
+// A FrameWriter is not safe for concurrent use.
+type FrameWriter struct {
+	output  io.Writer
+	format  Format
+	encoder Encoder
+	frame   []byte
+}
+
+// NewFrameWriter creates a FrameWriter which encodes messages in the given format and writes frames to output
+func NewFrameWriter(output io.Writer, f Format) *FrameWriter {
+	return &FrameWriter{
+		output:  output,
+		format:  f,
+		encoder: NewEncoderBytes(nil, f),
+	}
+}
+
+// Format returns the wrp format this writer encodes to
+func (fw *FrameWriter) Format() Format {
+	return fw.format
+}
+
+// Encode writes the given value, typically a *Message, as a single frame
+func (fw *FrameWriter) Encode(value interface{}) error {
+	fw.frame = fw.frame[:0]
+	fw.encoder.ResetBytes(&fw.frame)
+	if err := fw.encoder.Encode(value); err != nil {
+		return err
+	}
+
+	return WriteFrame(fw.output, fw.frame)
+}
+
+// FrameReader reads WRP messages from a stream of length-prefixed frames, such as that produced by
+// a FrameWriter.  A FrameReader reuses its Decoder and frame buffer across messages.
+//
+// Because each message is delimited by its frame, a message that cannot be decoded does not prevent
+// reading the rest of the stream.  This is the way to resume after a bad message in any format,
+// including Msgpack.  A plain Decoder reading from an io.Reader cannot resynchronize after such an error.
+//
+// A FrameReader is not safe for concurrent use.
+type FrameReader struct {
+	input   io.Reader
+	format  Format
+	decoder Decoder
+	frame   []byte
+
+	// offset is the number of bytes read from input so far
+	offset int64
+}
+
+// NewFrameReader creates a FrameReader which reads frames from input and decodes them using the given format
+func NewFrameReader(input io.Reader, f Format) *FrameReader {
+	return &FrameReader{
+		input:   input,
+		format:  f,
+		decoder: NewDecoderBytes(nil, f),
+	}
+}
+
+// Format returns the wrp format this reader decodes from
+func (fr *FrameReader) Format() Format {
+	return fr.format
+}
+
+// Decode reads the next frame onto the given value, typically a *Message.  When the stream ends cleanly
+// between frames, this method returns io.EOF.  If the stream ends partway through a frame, ErrShortFrame
+// is returned.
+//
+// If a frame cannot be decoded, the returned *DecodeError reports an offset from the start of the stream
+// rather than from the start of the frame.  The bad frame is skipped, so the next call to Decode resumes
+// with the following frame.
+func (fr *FrameReader) Decode(value interface{}) error {
+	frame, err := ReadFrame(fr.input, fr.frame)
+	if err == io.ErrUnexpectedEOF {
+		return ErrShortFrame
+	} else if err != nil {
+		return err
+	}
+
+	frameStart := fr.offset + FrameHeaderSize
+	fr.offset = frameStart + int64(len(frame))
+
+	fr.frame = frame
+	fr.decoder.ResetBytes(frame)
+	err = fr.decoder.Decode(value)
+	if decodeError, ok := err.(*DecodeError); ok {
+		decodeError.Offset += frameStart
+	}
+
+	return err
+}
@@ -0,0 +1,117 @@
+package wrp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFramesRoundTrip(t *testing.T, f Format) {
+	var (
+		assert   = assert.New(t)
+		require  = require.New(t)
+		messages = testTranscoderMessages()
+		stream   = new(bytes.Buffer)
+
+		writer = NewFrameWriter(stream, f)
+		reader = NewFrameReader(stream, f)
+	)
+
+	assert.Equal(f, writer.Format())
+	assert.Equal(f, reader.Format())
+
+	for _, message := range messages {
+		require.NoError(writer.Encode(message))
+	}
+
+	decoded := make([]*Message, 0, len(messages))
+	for {
+		message := new(Message)
+		err := reader.Decode(message)
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(err)
+		decoded = append(decoded, message)
+	}
+
+	assert.Equal(messages, decoded)
+	assert.Equal(io.EOF, reader.Decode(new(Message)))
+}
+
+func testFramesShortFrame(t *testing.T, f Format) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		stream  = new(bytes.Buffer)
+	)
+
+	require.NoError(NewFrameWriter(stream, f).Encode(testTranscoderMessages()[1]))
+	complete := stream.Bytes()
+
+	for _, size := range []int{1, FrameHeaderSize - 1, FrameHeaderSize, len(complete) - 1} {
+		reader := NewFrameReader(bytes.NewReader(complete[:size]), f)
+		assert.Equal(ErrShortFrame, reader.Decode(new(Message)), "size: %d", size)
+	}
+
+	assert.Equal(io.EOF, NewFrameReader(bytes.NewReader(nil), f).Decode(new(Message)))
+}
+
+func testFramesWriteError(t *testing.T, f Format) {
+	var (
+		assert        = assert.New(t)
+		expectedError = errors.New("expected")
+		writer        = NewFrameWriter(failingWriter{expectedError}, f)
+	)
+
+	assert.Equal(expectedError, writer.Encode(testTranscoderMessages()[0]))
+}
+
+func testFramesBadFrame(t *testing.T) {
+	var (
+		assert            = assert.New(t)
+		require           = require.New(t)
+		corrupt, position = corruptMsgpack(t)
+		single            = len(corrupt) / 3
+		stream            = new(bytes.Buffer)
+	)
+
+	// each of the three messages goes in its own frame, and the second one cannot be decoded
+	for i := 0; i < 3; i++ {
+		require.NoError(WriteFrame(stream, corrupt[i*single:(i+1)*single]))
+	}
+
+	reader := NewFrameReader(stream, Msgpack)
+	assert.NoError(reader.Decode(new(Message)))
+
+	err := reader.Decode(new(Message))
+	if decodeError, ok := err.(*DecodeError); assert.True(ok, "expected a *DecodeError, got %T", err) {
+		// the corruption is shifted by the length prefixes of the first two frames
+		corrupted := int64(position + 2*FrameHeaderSize)
+		assert.True(decodeError.Offset > corrupted, "offset %d is before the corruption at %d", decodeError.Offset, corrupted)
+		assert.True(decodeError.Offset <= corrupted+2, "offset %d is too far past the corruption at %d", decodeError.Offset, corrupted)
+	}
+
+	// the bad frame is skipped
+	message := new(Message)
+	assert.NoError(reader.Decode(message))
+	assert.Equal("source", message.Source)
+	assert.Equal(io.EOF, reader.Decode(new(Message)))
+}
+
+func TestFrames(t *testing.T) {
+	for _, f := range allFormats {
+		t.Run(f.String(), func(t *testing.T) {
+			t.Run("RoundTrip", func(t *testing.T) { testFramesRoundTrip(t, f) })
+			t.Run("ShortFrame", func(t *testing.T) { testFramesShortFrame(t, f) })
+			t.Run("WriteError", func(t *testing.T) { testFramesWriteError(t, f) })
+		})
+	}
+
+	t.Run("BadFrame", testFramesBadFrame)
+}
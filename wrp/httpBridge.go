@@ -0,0 +1,129 @@
+package wrp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ToHTTPRequest produces an HTTP request to a backend service from this message.  The message's Path is appended
+// to the path of baseURL, and may carry a query string.  Each of the message's Headers must be of the form "Name: value",
+// and becomes an HTTP header.  A message with a Payload produces a POST with the payload as the body and ContentType as
+// the Content-Type header.  A message without a Payload produces a GET.
+//
+// The returned request uses the given context, which must be non-nil.
+func (msg *Message) ToHTTPRequest(ctx context.Context, baseURL string) (*http.Request, error) {
+	target, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := url.Parse(msg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path.Path) > 0 {
+		target.Path = strings.TrimSuffix(target.Path, "/") + "/" + strings.TrimPrefix(path.Path, "/")
+		target.RawPath = ""
+	}
+
+	if len(path.RawQuery) > 0 {
+		target.RawQuery = path.RawQuery
+	}
+
+	var (
+		method = http.MethodGet
+		body   io.Reader
+	)
+
+	if len(msg.Payload) > 0 {
+		method = http.MethodPost
+		body = bytes.NewReader(msg.Payload)
+	}
+
+	request, err := http.NewRequest(method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range msg.Headers {
+		name, value, err := splitHeader(header)
+		if err != nil {
+			return nil, err
+		}
+
+		request.Header.Add(name, value)
+	}
+
+	if len(msg.Payload) > 0 && len(msg.ContentType) > 0 {
+		request.Header.Set("Content-Type", msg.ContentType)
+	}
+
+	if len(msg.Accept) > 0 {
+		request.Header.Set("Accept", msg.Accept)
+	}
+
+	return request.WithContext(ctx), nil
+}
+
+// FromHTTPResponse produces a SimpleRequestResponse message from a backend service's HTTP response.  The
+// response body is read fully and closed, and becomes the message's Payload.  The HTTP status code becomes the
+// message's Status, and the Content-Type header becomes ContentType.  All other HTTP headers are copied into
+// Headers as "Name: value" strings, sorted by name.
+//
+// Routing fields, such as Source, Destination, and TransactionUUID, are left for the caller to fill in.
+func FromHTTPResponse(resp *http.Response) (*Message, error) {
+	var payload []byte
+	if resp.Body != nil {
+		var err error
+		payload, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	message := &Message{
+		Type:        SimpleRequestResponseMessageType,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+
+	if len(payload) > 0 {
+		message.Payload = payload
+	}
+
+	message.SetStatus(int64(resp.StatusCode))
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		if http.CanonicalHeaderKey(name) != "Content-Type" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range resp.Header[name] {
+			message.Headers = append(message.Headers, name+": "+value)
+		}
+	}
+
+	return message, nil
+}
+
+// splitHeader parses a WRP header of the form "Name: value"
+func splitHeader(header string) (string, string, error) {
+	position := strings.IndexByte(header, ':')
+	if position < 1 {
+		return "", "", fmt.Errorf("Invalid WRP header: %s", header)
+	}
+
+	return strings.TrimSpace(header[:position]), strings.TrimSpace(header[position+1:]), nil
+}
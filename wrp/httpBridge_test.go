@@ -0,0 +1,122 @@
+package wrp
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testToHTTPRequestWithPayload(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		ctx     = context.WithValue(context.Background(), "foo", "bar")
+
+		message = Message{
+			Type:        SimpleRequestResponseMessageType,
+			ContentType: "application/json",
+			Accept:      "text/plain",
+			Headers:     []string{"X-Test: value1", "X-Test:value2", "X-Other: a: b"},
+			Path:        "/config/device?names=a,b",
+			Payload:     []byte(`{"foo": "bar"}`),
+		}
+	)
+
+	request, err := message.ToHTTPRequest(ctx, "http://backend.example.com/api/v2/")
+	require.NoError(err)
+	require.NotNil(request)
+
+	assert.Equal(ctx, request.Context())
+	assert.Equal(http.MethodPost, request.Method)
+	assert.Equal("http://backend.example.com/api/v2/config/device?names=a,b", request.URL.String())
+	assert.Equal([]string{"value1", "value2"}, request.Header["X-Test"])
+	assert.Equal("a: b", request.Header.Get("X-Other"))
+	assert.Equal("application/json", request.Header.Get("Content-Type"))
+	assert.Equal("text/plain", request.Header.Get("Accept"))
+
+	body, err := ioutil.ReadAll(request.Body)
+	require.NoError(err)
+	assert.Equal(message.Payload, body)
+}
+
+func testToHTTPRequestNoPayload(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		message = Message{
+			Type:        SimpleRequestResponseMessageType,
+			ContentType: "application/json",
+			Path:        "status",
+		}
+	)
+
+	request, err := message.ToHTTPRequest(context.Background(), "http://backend.example.com")
+	require.NoError(err)
+	require.NotNil(request)
+
+	assert.Equal(http.MethodGet, request.Method)
+	assert.Equal("http://backend.example.com/status", request.URL.String())
+	assert.Empty(request.Header.Get("Content-Type"))
+}
+
+func testToHTTPRequestInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, message := range []Message{{Headers: []string{"no colon"}}, {Headers: []string{": no name"}}} {
+		request, err := message.ToHTTPRequest(context.Background(), "http://backend.example.com")
+		assert.Nil(request)
+		assert.Error(err)
+	}
+
+	request, err := new(Message).ToHTTPRequest(context.Background(), "http://[::1")
+	assert.Nil(request)
+	assert.Error(err)
+}
+
+func TestToHTTPRequest(t *testing.T) {
+	t.Run("WithPayload", testToHTTPRequestWithPayload)
+	t.Run("NoPayload", testToHTTPRequestNoPayload)
+	t.Run("Invalid", testToHTTPRequestInvalid)
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		response = &http.Response{
+			StatusCode: http.StatusAccepted,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+				"X-Test":       []string{"value1", "value2"},
+				"Etag":         []string{"abc"},
+			},
+			Body: ioutil.NopCloser(bytes.NewBufferString(`{"foo": "bar"}`)),
+		}
+	)
+
+	message, err := FromHTTPResponse(response)
+	require.NoError(err)
+	require.NotNil(message)
+
+	assert.Equal(
+		(&Message{
+			Type:        SimpleRequestResponseMessageType,
+			ContentType: "application/json",
+			Headers:     []string{"Etag: abc", "X-Test: value1", "X-Test: value2"},
+			Payload:     []byte(`{"foo": "bar"}`),
+		}).SetStatus(http.StatusAccepted),
+		message,
+	)
+
+	message, err = FromHTTPResponse(&http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}})
+	require.NoError(err)
+	require.NotNil(message)
+	assert.Equal((&Message{Type: SimpleRequestResponseMessageType}).SetStatus(http.StatusNoContent), message)
+}
@@ -0,0 +1,96 @@
+package wrp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode"
+)
+
+// JSONLinesEncoder writes WRP messages as newline-delimited JSON, one message per line.
+// This format is intended for human-inspectable captures of WRP traffic rather than for
+// transmission.  Payloads are base64-encoded by the JSON format, so a payload containing
+// newlines will never break the one-message-per-line layout.
+//
+// A JSONLinesEncoder is not safe for concurrent use.
+type JSONLinesEncoder struct {
+	output  io.Writer
+	encoder Encoder
+	line    []byte
+}
+
+// NewJSONLinesEncoder creates a JSONLinesEncoder which writes to the given output
+func NewJSONLinesEncoder(output io.Writer) *JSONLinesEncoder {
+	return &JSONLinesEncoder{
+		output:  output,
+		encoder: NewEncoderBytes(nil, JSON),
+	}
+}
+
+// Encode writes the given value, typically a *Message, as a single line of JSON.  Each line
+// is written to the underlying io.Writer with exactly (1) call to Write.
+func (e *JSONLinesEncoder) Encode(value interface{}) error {
+	e.line = e.line[:0]
+	e.encoder.ResetBytes(&e.line)
+	if err := e.encoder.Encode(value); err != nil {
+		return err
+	}
+
+	e.line = append(e.line, '\n')
+	_, err := e.output.Write(e.line)
+	return err
+}
+
+// JSONLinesDecoder reads WRP messages from newline-delimited JSON, such as that produced
+// by a JSONLinesEncoder.  Blank lines are ignored.
+//
+// A JSONLinesDecoder is not safe for concurrent use.
+type JSONLinesDecoder struct {
+	input   *bufio.Reader
+	decoder Decoder
+
+	// offset is the number of bytes read from input so far
+	offset int64
+}
+
+// NewJSONLinesDecoder creates a JSONLinesDecoder which reads from the given input
+func NewJSONLinesDecoder(input io.Reader) *JSONLinesDecoder {
+	return &JSONLinesDecoder{
+		input:   bufio.NewReader(input),
+		decoder: NewDecoderBytes(nil, JSON),
+	}
+}
+
+// Decode reads the next line of JSON onto the given value, typically a *Message.
+// When no more lines are available, this method returns io.EOF.
+//
+// If a line cannot be decoded, the returned *DecodeError reports an offset from the start of
+// the input rather than from the start of the line.  The bad line is skipped, so the next call
+// to Decode resumes with the following line.
+func (d *JSONLinesDecoder) Decode(value interface{}) error {
+	for {
+		line, err := d.input.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		lineStart := d.offset
+		d.offset += int64(len(line))
+
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			d.decoder.ResetBytes(trimmed)
+			err = d.decoder.Decode(value)
+			if decodeError, ok := err.(*DecodeError); ok {
+				leadingSpace := len(line) - len(bytes.TrimLeftFunc(line, unicode.IsSpace))
+				decodeError.Offset += lineStart + int64(leadingSpace)
+			}
+
+			return err
+		}
+
+		if err == io.EOF {
+			return io.EOF
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package wrp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLines(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		expectedStatus int64 = 200
+		messages             = []Message{
+			{
+				Type:        SimpleEventMessageType,
+				Source:      "mac:121234345656",
+				Destination: "event:device-status",
+				Payload:     []byte("line one\nline two\n"),
+			},
+			{
+				Type:            SimpleRequestResponseMessageType,
+				Source:          "dns:talaria.comcast.net",
+				Destination:     "mac:112233445566/config",
+				TransactionUUID: "1-2-3-4",
+				Status:          &expectedStatus,
+				Metadata:        map[string]string{"multiline": "a\nb"},
+				Payload:         []byte{0x0a, 0x00, 0xff, 0x0d, 0x0a},
+			},
+			{
+				Type: ServiceAliveMessageType,
+			},
+		}
+
+		output  bytes.Buffer
+		encoder = NewJSONLinesEncoder(&output)
+	)
+
+	for i := range messages {
+		require.NoError(encoder.Encode(&messages[i]))
+	}
+
+	assert.Equal(len(messages), strings.Count(output.String(), "\n"))
+
+	decoder := NewJSONLinesDecoder(&output)
+	for _, expected := range messages {
+		var actual Message
+		require.NoError(decoder.Decode(&actual))
+		assert.Equal(expected, actual)
+	}
+
+	var extra Message
+	assert.Equal(io.EOF, decoder.Decode(&extra))
+}
+
+func TestJSONLinesDecoder(t *testing.T) {
+	t.Run("BlankLines", func(t *testing.T) {
+		var (
+			assert  = assert.New(t)
+			decoder = NewJSONLinesDecoder(strings.NewReader("\n{\"msg_type\": 3, \"source\": \"foo\"}\n\n{\"msg_type\": 4}"))
+			first   Message
+			second  Message
+			third   Message
+		)
+
+		assert.NoError(decoder.Decode(&first))
+		assert.Equal(Message{Type: SimpleRequestResponseMessageType, Source: "foo"}, first)
+		assert.NoError(decoder.Decode(&second))
+		assert.Equal(Message{Type: SimpleEventMessageType}, second)
+		assert.Equal(io.EOF, decoder.Decode(&third))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		var message Message
+		assert.Equal(t, io.EOF, NewJSONLinesDecoder(new(bytes.Buffer)).Decode(&message))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		var message Message
+		assert.Error(t, NewJSONLinesDecoder(strings.NewReader("this is not JSON\n")).Decode(&message))
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		var (
+			assert  = assert.New(t)
+			input   = "{\"msg_type\": 4}\n  {\"msg_type\": 4, zz}\n{\"msg_type\": 3}\n"
+			decoder = NewJSONLinesDecoder(strings.NewReader(input))
+			message Message
+		)
+
+		assert.NoError(decoder.Decode(&message))
+		assert.Equal(Message{Type: SimpleEventMessageType}, message)
+
+		err := decoder.Decode(new(Message))
+		if decodeError, ok := err.(*DecodeError); assert.True(ok, "expected a *DecodeError, got %T", err) {
+			assert.Equal(JSON, decodeError.Format)
+
+			// the offset is relative to the input, and falls within the bad line
+			badLine := int64(strings.Index(input, "  {"))
+			assert.True(decodeError.Offset > badLine+2, "offset %d is before the bad line", decodeError.Offset)
+			assert.True(decodeError.Offset <= int64(strings.Index(input, "\n{\"msg_type\": 3")), "offset %d is after the bad line", decodeError.Offset)
+		}
+
+		message = Message{}
+		assert.NoError(decoder.Decode(&message))
+		assert.Equal(Message{Type: SimpleRequestResponseMessageType}, message)
+		assert.Equal(io.EOF, decoder.Decode(new(Message)))
+	})
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (fw failingWriter) Write([]byte) (int, error) {
+	return 0, fw.err
+}
+
+func TestJSONLinesEncoderWriteError(t *testing.T) {
+	var (
+		assert        = assert.New(t)
+		expectedError = errors.New("expected")
+		encoder       = NewJSONLinesEncoder(failingWriter{expectedError})
+	)
+
+	assert.Equal(expectedError, encoder.Encode(&Message{Type: SimpleEventMessageType}))
+}
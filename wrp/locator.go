@@ -0,0 +1,106 @@
+package wrp
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MacScheme is the locator scheme for devices identified by MAC address
+	MacScheme = "mac"
+
+	// UUIDScheme is the locator scheme for devices identified by UUID
+	UUIDScheme = "uuid"
+
+	// DNSScheme is the locator scheme for servers identified by DNS name
+	DNSScheme = "dns"
+
+	// SerialScheme is the locator scheme for devices identified by serial number
+	SerialScheme = "serial"
+
+	// EventScheme is the locator scheme for events
+	EventScheme = "event"
+
+	// SelfScheme is the locator scheme a device uses to refer to itself.  A self locator
+	// has no identifier, e.g. self:/config.
+	SelfScheme = "self"
+
+	hexDigits     = "0123456789abcdefABCDEF"
+	macDelimiters = ":-.,"
+
+	// macLength is the number of hexadecimal digits in a mac locator, ignoring delimiters
+	macLength = 12
+)
+
+var (
+	ErrLocatorMissingScheme     = errors.New("The locator has no scheme")
+	ErrLocatorUnsupportedScheme = errors.New("The locator scheme is not supported")
+	ErrLocatorEmptyID           = errors.New("The locator has an empty identifier")
+	ErrLocatorInvalidMAC        = errors.New("The locator has an invalid MAC address")
+)
+
+// locatorSchemes are the schemes allowed for source and destination locators
+var locatorSchemes = map[string]bool{
+	MacScheme:    true,
+	UUIDScheme:   true,
+	DNSScheme:    true,
+	SerialScheme: true,
+	EventScheme:  true,
+	SelfScheme:   true,
+}
+
+// ParseLocator parses a source or destination of the form {scheme}:{id}[/{service}...].  The returned scheme is
+// lowercased, and the returned id excludes any service or path.  For mac locators, the id must consist of exactly
+// 12 hexadecimal digits, optionally separated by any of the characters ':', '-', '.', or ','.  The returned mac id is
+// normalized to lowercase with the delimiters removed.
+//
+// Every scheme except self requires a nonempty id.
+func ParseLocator(locator string) (scheme, id string, err error) {
+	position := strings.IndexByte(locator, ':')
+	if position < 0 {
+		return "", "", ErrLocatorMissingScheme
+	}
+
+	scheme = strings.ToLower(locator[:position])
+	if !locatorSchemes[scheme] {
+		return "", "", ErrLocatorUnsupportedScheme
+	}
+
+	id = locator[position+1:]
+	if slash := strings.IndexByte(id, '/'); slash >= 0 {
+		id = id[:slash]
+	}
+
+	if len(id) == 0 {
+		if scheme == SelfScheme {
+			return scheme, id, nil
+		}
+
+		return "", "", ErrLocatorEmptyID
+	}
+
+	if scheme == MacScheme {
+		invalid := false
+		id = strings.Map(
+			func(r rune) rune {
+				switch {
+				case strings.ContainsRune(hexDigits, r):
+					return unicode.ToLower(r)
+				case strings.ContainsRune(macDelimiters, r):
+					return -1
+				default:
+					invalid = true
+					return -1
+				}
+			},
+			id,
+		)
+
+		if invalid || len(id) != macLength {
+			return "", "", ErrLocatorInvalidMAC
+		}
+	}
+
+	return scheme, id, nil
+}
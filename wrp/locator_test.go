@@ -0,0 +1,46 @@
+package wrp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocator(t *testing.T) {
+	testData := []struct {
+		locator        string
+		expectedScheme string
+		expectedID     string
+		expectedError  error
+	}{
+		{"mac:112233445566", MacScheme, "112233445566", nil},
+		{"MAC:11:22:33:44:55:66", MacScheme, "112233445566", nil},
+		{"mac:11-aa-BB-44-55-66/config", MacScheme, "11aabb445566", nil},
+		{"mac:11,aa,BB,44,55,66/service/path", MacScheme, "11aabb445566", nil},
+		{"uuid:c2bb1f16-09c8-11e7-93ae-92361f002671", UUIDScheme, "c2bb1f16-09c8-11e7-93ae-92361f002671", nil},
+		{"DNS:talaria.comcast.net:8080/api", DNSScheme, "talaria.comcast.net:8080", nil},
+		{"serial:1234/service", SerialScheme, "1234", nil},
+		{"event:device-status/mac:112233445566/online", EventScheme, "device-status", nil},
+		{"self:/config", SelfScheme, "", nil},
+		{"self:", SelfScheme, "", nil},
+		{"", "", "", ErrLocatorMissingScheme},
+		{"no scheme", "", "", ErrLocatorMissingScheme},
+		{"ftp:example.com", "", "", ErrLocatorUnsupportedScheme},
+		{":example.com", "", "", ErrLocatorUnsupportedScheme},
+		{"dns:", "", "", ErrLocatorEmptyID},
+		{"uuid:/service", "", "", ErrLocatorEmptyID},
+		{"mac:1122334455", "", "", ErrLocatorInvalidMAC},
+		{"mac:1122334455667788", "", "", ErrLocatorInvalidMAC},
+		{"mac:11223344556g", "", "", ErrLocatorInvalidMAC},
+	}
+
+	for _, record := range testData {
+		t.Run(record.locator, func(t *testing.T) {
+			assert := assert.New(t)
+			scheme, id, err := ParseLocator(record.locator)
+			assert.Equal(record.expectedScheme, scheme)
+			assert.Equal(record.expectedID, id)
+			assert.Equal(record.expectedError, err)
+		})
+	}
+}
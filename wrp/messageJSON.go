@@ -0,0 +1,27 @@
+package wrp
+
+import "github.com/ugorji/go/codec"
+
+// messageJSON has the same fields and wrp tags as Message but none of its methods.  Encoding
+// and decoding through this type lets the JSON methods of Message use the WRP codec without recursing.
+type messageJSON Message
+
+// MarshalJSON encodes this message exactly as a JSON Encoder from this package would, so that
+// encoding/json and other consumers agree on a single JSON form.  In that form, the Payload is
+// always standard base64 and Metadata keys are always sorted, which means a given message always
+// produces the same bytes regardless of the format it was originally decoded from.
+func (msg *Message) MarshalJSON() ([]byte, error) {
+	if err := msg.BeforeEncode(); err != nil {
+		return nil, err
+	}
+
+	var output []byte
+	err := codec.NewEncoderBytes(&output, &jsonHandle).Encode((*messageJSON)(msg))
+	return output, err
+}
+
+// UnmarshalJSON decodes the JSON form of a message produced by MarshalJSON or by a JSON Encoder
+// from this package.
+func (msg *Message) UnmarshalJSON(data []byte) error {
+	return codec.NewDecoderBytes(data, &jsonHandle).Decode((*messageJSON)(msg))
+}
@@ -0,0 +1,111 @@
+package wrp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessageJSONPayload(t *testing.T, payload []byte) {
+	var (
+		assert   = assert.New(t)
+		require  = require.New(t)
+		original = Message{
+			Type:        SimpleEventMessageType,
+			Source:      "mac:112233445566",
+			Destination: "event:device-status",
+			Payload:     payload,
+		}
+
+		fromMsgpack Message
+		encoded     []byte
+	)
+
+	// a message decoded from msgpack must always produce the same JSON
+	require.NoError(NewEncoderBytes(&encoded, Msgpack).Encode(&original))
+	require.NoError(NewDecoderBytes(encoded, Msgpack).Decode(&fromMsgpack))
+
+	first, err := json.Marshal(&fromMsgpack)
+	require.NoError(err)
+	second, err := json.Marshal(&fromMsgpack)
+	require.NoError(err)
+	assert.Equal(first, second)
+
+	var wrpJSON []byte
+	require.NoError(NewEncoderBytes(&wrpJSON, JSON).Encode(&fromMsgpack))
+	assert.Equal(wrpJSON, first)
+
+	var raw map[string]interface{}
+	require.NoError(json.Unmarshal(first, &raw))
+	if len(payload) > 0 {
+		assert.Equal(base64.StdEncoding.EncodeToString(payload), raw["payload"])
+	} else {
+		assert.NotContains(raw, "payload")
+	}
+
+	var decoded Message
+	require.NoError(json.Unmarshal(first, &decoded))
+	assert.Equal(fromMsgpack, decoded)
+
+	// the JSON form transcodes back to the original msgpack bytes
+	var reencoded []byte
+	require.NoError(NewEncoderBytes(&reencoded, Msgpack).Encode(&decoded))
+	assert.Equal(encoded, reencoded)
+}
+
+func testMessageJSONMetadataOrder(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+		message = Message{
+			Type:     SimpleEventMessageType,
+			Metadata: make(map[string]string),
+			Spans:    [][]string{{"second", "2", "3"}, {"first", "1", "2"}},
+		}
+	)
+
+	for _, key := range []string{"zulu", "alpha", "mike", "bravo", "yankee", "charlie", "xray", "delta"} {
+		message.Metadata[key] = key
+	}
+
+	expected, err := json.Marshal(&message)
+	require.NoError(err)
+	assert.Contains(string(expected), `"metadata":{"alpha":"alpha","bravo":"bravo","charlie":"charlie","delta":"delta","mike":"mike","xray":"xray","yankee":"yankee","zulu":"zulu"}`)
+	assert.Contains(string(expected), `"spans":[["second","2","3"],["first","1","2"]]`)
+
+	for repeat := 0; repeat < 10; repeat++ {
+		actual, err := json.Marshal(&message)
+		require.NoError(err)
+		assert.Equal(expected, actual)
+	}
+}
+
+func testMessageJSONInvalid(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		message = new(Message).SetQualityOfService(MaxQualityOfService + 1)
+	)
+
+	data, err := json.Marshal(message)
+	assert.Empty(data)
+	assert.Error(err)
+
+	assert.Error(json.Unmarshal([]byte(`{"msg_type": "this is not a message type"}`), new(Message)))
+}
+
+func TestMessageJSON(t *testing.T) {
+	t.Run("Payload", func(t *testing.T) {
+		t.Run("Nil", func(t *testing.T) { testMessageJSONPayload(t, nil) })
+		t.Run("Empty", func(t *testing.T) { testMessageJSONPayload(t, []byte{}) })
+		t.Run("Text", func(t *testing.T) { testMessageJSONPayload(t, []byte("this is clearly a UTF8 string")) })
+		t.Run("ControlCharacters", func(t *testing.T) {
+			testMessageJSONPayload(t, []byte{0x00, 0x01, 0x07, '\n', '\r', '\t', 0x1B, '"', '\\', 0x7F, 0xFF})
+		})
+	})
+
+	t.Run("MetadataOrder", testMessageJSONMetadataOrder)
+	t.Run("Invalid", testMessageJSONInvalid)
+}
@@ -2,6 +2,33 @@ package wrp
 
 //go:generate codecgen -st "wrp" -o messages_codec.go messages.go
 
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// MinQualityOfService is the smallest value allowed for the optional qos field
+	MinQualityOfService = 0
+
+	// MaxQualityOfService is the largest value allowed for the optional qos field
+	MaxQualityOfService = 99
+)
+
+var (
+	ErrInvalidQualityOfService = errors.New("The quality of service value is out of range")
+	ErrCRUDMissingPath         = errors.New("A CRUD message requires a path")
+)
+
+// validateQualityOfService checks an optional qos value against the range allowed by the WRP spec
+func validateQualityOfService(value *int64) error {
+	if value != nil && (*value < MinQualityOfService || *value > MaxQualityOfService) {
+		return fmt.Errorf("%s: %d", ErrInvalidQualityOfService, *value)
+	}
+
+	return nil
+}
+
 // Typed is implemented by any WRP type which is associated with a MessageType.  All
 // message types implement this interface.
 type Typed interface {
@@ -71,6 +98,8 @@ type Message struct {
 	Accept                  string            `wrp:"accept,omitempty"`
 	Status                  *int64            `wrp:"status,omitempty"`
 	RequestDeliveryResponse *int64            `wrp:"rdr,omitempty"`
+	QualityOfService        *int64            `wrp:"qos,omitempty"`
+	PartnerIDs              []string          `wrp:"partner_ids,omitempty"`
 	Headers                 []string          `wrp:"headers,omitempty"`
 	Metadata                map[string]string `wrp:"metadata,omitempty"`
 	Spans                   [][]string        `wrp:"spans,omitempty"`
@@ -81,6 +110,12 @@ type Message struct {
 	URL                     string            `wrp:"url,omitempty"`
 }
 
+// BeforeEncode verifies that the optional QualityOfService, if set, is within the range allowed by the spec.
+// Unlike the other message types, Message does not set its Type, since it can represent any message.
+func (msg *Message) BeforeEncode() error {
+	return validateQualityOfService(msg.QualityOfService)
+}
+
 func (msg *Message) MessageType() MessageType {
 	return msg.Type
 }
@@ -129,6 +164,69 @@ func (msg *Message) SetIncludeSpans(value bool) *Message {
 	return msg
 }
 
+// SetQualityOfService simplifies setting the optional QualityOfService field, which is a pointer type tagged with omitempty.
+func (msg *Message) SetQualityOfService(value int64) *Message {
+	msg.QualityOfService = &value
+	return msg
+}
+
+// Clone returns a deep copy of this message.  The clone shares no memory with this message, so mutating
+// the clone's slices, Metadata, or pointer fields never affects this message and vice versa.  Nil slices,
+// maps, and pointers remain nil in the clone.
+func (msg *Message) Clone() *Message {
+	clone := *msg
+	clone.Status = cloneInt64(msg.Status)
+	clone.RequestDeliveryResponse = cloneInt64(msg.RequestDeliveryResponse)
+	clone.QualityOfService = cloneInt64(msg.QualityOfService)
+	clone.PartnerIDs = cloneStrings(msg.PartnerIDs)
+	clone.Headers = cloneStrings(msg.Headers)
+
+	if msg.Metadata != nil {
+		clone.Metadata = make(map[string]string, len(msg.Metadata))
+		for key, value := range msg.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+
+	if msg.Spans != nil {
+		clone.Spans = make([][]string, len(msg.Spans))
+		for i, span := range msg.Spans {
+			clone.Spans[i] = cloneStrings(span)
+		}
+	}
+
+	if msg.IncludeSpans != nil {
+		includeSpans := *msg.IncludeSpans
+		clone.IncludeSpans = &includeSpans
+	}
+
+	if msg.Payload != nil {
+		clone.Payload = make([]byte, len(msg.Payload))
+		copy(clone.Payload, msg.Payload)
+	}
+
+	return &clone
+}
+
+func cloneInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+
+	clone := *v
+	return &clone
+}
+
+func cloneStrings(v []string) []string {
+	if v == nil {
+		return nil
+	}
+
+	clone := make([]string, len(v))
+	copy(clone, v)
+	return clone
+}
+
 // AuthorizationStatus represents a WRP message of type AuthMessageType.
 //
 // https://github.com/Comcast/wrp-c/wiki/Web-Routing-Protocol#authorization-status-definition
@@ -162,6 +260,8 @@ type SimpleRequestResponse struct {
 	TransactionUUID         string            `wrp:"transaction_uuid,omitempty"`
 	Status                  *int64            `wrp:"status,omitempty"`
 	RequestDeliveryResponse *int64            `wrp:"rdr,omitempty"`
+	QualityOfService        *int64            `wrp:"qos,omitempty"`
+	PartnerIDs              []string          `wrp:"partner_ids,omitempty"`
 	Headers                 []string          `wrp:"headers,omitempty"`
 	Metadata                map[string]string `wrp:"metadata,omitempty"`
 	Spans                   [][]string        `wrp:"spans,omitempty"`
@@ -187,9 +287,15 @@ func (msg *SimpleRequestResponse) SetIncludeSpans(value bool) *SimpleRequestResp
 	return msg
 }
 
+// SetQualityOfService simplifies setting the optional QualityOfService field, which is a pointer type tagged with omitempty.
+func (msg *SimpleRequestResponse) SetQualityOfService(value int64) *SimpleRequestResponse {
+	msg.QualityOfService = &value
+	return msg
+}
+
 func (msg *SimpleRequestResponse) BeforeEncode() error {
 	msg.Type = SimpleRequestResponseMessageType
-	return nil
+	return validateQualityOfService(msg.QualityOfService)
 }
 
 func (msg *SimpleRequestResponse) MessageType() MessageType {
@@ -232,18 +338,26 @@ func (msg *SimpleRequestResponse) Response(newSource string, requestDeliveryResp
 type SimpleEvent struct {
 	// Type is exposed principally for encoding.  This field *must* be set to SimpleEventMessageType,
 	// and is automatically set by the BeforeEncode method.
-	Type        MessageType       `wrp:"msg_type"`
-	Source      string            `wrp:"source"`
-	Destination string            `wrp:"dest"`
-	ContentType string            `wrp:"content_type,omitempty"`
-	Headers     []string          `wrp:"headers,omitempty"`
-	Metadata    map[string]string `wrp:"metadata,omitempty"`
-	Payload     []byte            `wrp:"payload,omitempty"`
+	Type             MessageType       `wrp:"msg_type"`
+	Source           string            `wrp:"source"`
+	Destination      string            `wrp:"dest"`
+	ContentType      string            `wrp:"content_type,omitempty"`
+	QualityOfService *int64            `wrp:"qos,omitempty"`
+	PartnerIDs       []string          `wrp:"partner_ids,omitempty"`
+	Headers          []string          `wrp:"headers,omitempty"`
+	Metadata         map[string]string `wrp:"metadata,omitempty"`
+	Payload          []byte            `wrp:"payload,omitempty"`
+}
+
+// SetQualityOfService simplifies setting the optional QualityOfService field, which is a pointer type tagged with omitempty.
+func (msg *SimpleEvent) SetQualityOfService(value int64) *SimpleEvent {
+	msg.QualityOfService = &value
+	return msg
 }
 
 func (msg *SimpleEvent) BeforeEncode() error {
 	msg.Type = SimpleEventMessageType
-	return nil
+	return validateQualityOfService(msg.QualityOfService)
 }
 
 func (msg *SimpleEvent) MessageType() MessageType {
@@ -277,7 +391,8 @@ func (msg *SimpleEvent) Response(newSource string, requestDeliveryResponse int64
 }
 
 // CRUD represents a WRP message of one of the CRUD message types.  This type does not implement BeforeEncode,
-// and so does not automatically set the Type field.  Client code must set the Type code appropriately.
+// and so does not automatically set the Type field.  Client code must set the Type code appropriately, which
+// is most easily done by creating CRUD messages with NewCreate, NewRetrieve, NewUpdate, or NewDelete.
 //
 // https://github.com/Comcast/wrp-c/wiki/Web-Routing-Protocol#crud-message-definition
 type CRUD struct {
@@ -286,6 +401,7 @@ type CRUD struct {
 	Destination             string            `wrp:"dest"`
 	TransactionUUID         string            `wrp:"transaction_uuid,omitempty"`
 	ContentType             string            `wrp:"content_type,omitempty"`
+	PartnerIDs              []string          `wrp:"partner_ids,omitempty"`
 	Headers                 []string          `wrp:"headers,omitempty"`
 	Metadata                map[string]string `wrp:"metadata,omitempty"`
 	Spans                   [][]string        `wrp:"spans,omitempty"`
@@ -296,6 +412,39 @@ type CRUD struct {
 	Payload                 []byte            `wrp:"payload,omitempty"`
 }
 
+// newCRUD creates a CRUD message of the given type, ensuring that the path is not empty
+func newCRUD(mt MessageType, path string) (*CRUD, error) {
+	if len(path) == 0 {
+		return nil, ErrCRUDMissingPath
+	}
+
+	return &CRUD{Type: mt, Path: path}, nil
+}
+
+// NewCreate returns a CRUD message with its Type set to CreateMessageType and the given path.
+// The path cannot be empty.
+func NewCreate(path string) (*CRUD, error) {
+	return newCRUD(CreateMessageType, path)
+}
+
+// NewRetrieve returns a CRUD message with its Type set to RetrieveMessageType and the given path.
+// The path cannot be empty.
+func NewRetrieve(path string) (*CRUD, error) {
+	return newCRUD(RetrieveMessageType, path)
+}
+
+// NewUpdate returns a CRUD message with its Type set to UpdateMessageType and the given path.
+// The path cannot be empty.
+func NewUpdate(path string) (*CRUD, error) {
+	return newCRUD(UpdateMessageType, path)
+}
+
+// NewDelete returns a CRUD message with its Type set to DeleteMessageType and the given path.
+// The path cannot be empty.
+func NewDelete(path string) (*CRUD, error) {
+	return newCRUD(DeleteMessageType, path)
+}
+
 // SetStatus simplifies setting the optional Status field, which is a pointer type tagged with omitempty.
 func (msg *CRUD) SetStatus(value int64) *CRUD {
 	msg.Status = &value
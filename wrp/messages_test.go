@@ -3,6 +3,7 @@ package wrp
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,6 +60,78 @@ func testMessageSetIncludeSpans(t *testing.T) {
 	assert.Equal(false, *message.IncludeSpans)
 }
 
+func testMessageSetQualityOfService(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		message Message
+	)
+
+	assert.Nil(message.QualityOfService)
+	assert.True(&message == message.SetQualityOfService(MaxQualityOfService))
+	assert.NotNil(message.QualityOfService)
+	assert.Equal(int64(MaxQualityOfService), *message.QualityOfService)
+	assert.True(&message == message.SetQualityOfService(0))
+	assert.NotNil(message.QualityOfService)
+	assert.Equal(int64(0), *message.QualityOfService)
+}
+
+func testMessageCloneIndependent(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		original = new(Message).
+				SetStatus(200).
+				SetRequestDeliveryResponse(1).
+				SetIncludeSpans(true).
+				SetQualityOfService(25)
+	)
+
+	original.PartnerIDs = []string{"comcast"}
+	original.Headers = make([]string, 1, 10)
+	original.Headers[0] = "Header1"
+	original.Metadata = map[string]string{"name": "value"}
+	original.Spans = [][]string{{"span", "1", "2"}}
+	original.Payload = []byte{1, 2, 3}
+
+	var (
+		expected = *original
+		clone    = original.Clone()
+	)
+
+	require.NotNil(clone)
+	require.True(original != clone)
+	assert.Equal(*original, *clone)
+
+	*clone.Status = 500
+	*clone.RequestDeliveryResponse = 2
+	*clone.IncludeSpans = false
+	*clone.QualityOfService = 99
+	clone.PartnerIDs[0] = "changed"
+	clone.PartnerIDs = append(clone.PartnerIDs, "appended")
+	clone.Headers[0] = "changed"
+	clone.Headers = append(clone.Headers, "appended")
+	clone.Metadata["name"] = "changed"
+	clone.Metadata["new"] = "added"
+	clone.Spans[0][0] = "changed"
+	clone.Spans = append(clone.Spans, []string{"appended"})
+	clone.Payload[0] = 0xff
+	clone.Payload = append(clone.Payload, 0xfe)
+
+	assert.Equal(expected, *original)
+	assert.Equal(int64(200), *original.Status)
+	assert.Equal(int64(1), *original.RequestDeliveryResponse)
+	assert.True(*original.IncludeSpans)
+	assert.Equal(int64(25), *original.QualityOfService)
+	assert.Equal([]string{"comcast"}, original.PartnerIDs)
+	assert.Equal([]string{"Header1"}, original.Headers)
+	assert.Equal("Header1", original.Headers[:2][0])
+	assert.Empty(original.Headers[1:2][0])
+	assert.Equal(map[string]string{"name": "value"}, original.Metadata)
+	assert.Equal([][]string{{"span", "1", "2"}}, original.Spans)
+	assert.Equal([]byte{1, 2, 3}, original.Payload)
+}
+
 func testMessageRoutable(t *testing.T, original Message) {
 	var (
 		assert  = assert.New(t)
@@ -108,6 +181,7 @@ func TestMessage(t *testing.T) {
 	t.Run("SetStatus", testMessageSetStatus)
 	t.Run("SetRequestDeliveryResponse", testMessageSetRequestDeliveryResponse)
 	t.Run("SetIncludeSpans", testMessageSetIncludeSpans)
+	t.Run("SetQualityOfService", testMessageSetQualityOfService)
 
 	var (
 		expectedStatus                  int64 = 3471
@@ -140,6 +214,7 @@ func TestMessage(t *testing.T) {
 				Source:          "external.com",
 				Destination:     "mac:FFEEAADD44443333",
 				TransactionUUID: "DEADBEEF",
+				PartnerIDs:      []string{"comcast", "partner"},
 				Headers:         []string{"Header1", "Header2"},
 				Metadata:        map[string]string{"name": "value"},
 				Spans:           [][]string{{"1", "2"}, {"3"}},
@@ -161,6 +236,15 @@ func TestMessage(t *testing.T) {
 		}
 	})
 
+	t.Run("Clone", func(t *testing.T) {
+		assert := assert.New(t)
+		for _, message := range messages {
+			assert.Equal(message, *message.Clone())
+		}
+
+		t.Run("Independent", testMessageCloneIndependent)
+	})
+
 	for _, source := range allFormats {
 		t.Run(fmt.Sprintf("Encode%s", source), func(t *testing.T) {
 			for _, message := range messages {
@@ -245,6 +329,21 @@ func testSimpleRequestResponseSetIncludeSpans(t *testing.T) {
 	assert.Equal(false, *message.IncludeSpans)
 }
 
+func testSimpleRequestResponseSetQualityOfService(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		message SimpleRequestResponse
+	)
+
+	assert.Nil(message.QualityOfService)
+	assert.True(&message == message.SetQualityOfService(MaxQualityOfService))
+	assert.NotNil(message.QualityOfService)
+	assert.Equal(int64(MaxQualityOfService), *message.QualityOfService)
+	assert.True(&message == message.SetQualityOfService(0))
+	assert.NotNil(message.QualityOfService)
+	assert.Equal(int64(0), *message.QualityOfService)
+}
+
 func testSimpleRequestResponseRoutable(t *testing.T, original SimpleRequestResponse) {
 	var (
 		assert  = assert.New(t)
@@ -295,6 +394,7 @@ func TestSimpleRequestResponse(t *testing.T) {
 	t.Run("SetStatus", testSimpleRequestResponseSetStatus)
 	t.Run("SetRequestDeliveryResponse", testSimpleRequestResponseSetRequestDeliveryResponse)
 	t.Run("SetIncludeSpans", testSimpleRequestResponseSetIncludeSpans)
+	t.Run("SetQualityOfService", testSimpleRequestResponseSetQualityOfService)
 
 	var (
 		expectedStatus                  int64 = 121
@@ -320,6 +420,7 @@ func TestSimpleRequestResponse(t *testing.T) {
 				Source:          "external.com",
 				Destination:     "mac:FFEEAADD44443333",
 				TransactionUUID: "DEADBEEF",
+				PartnerIDs:      []string{"comcast", "partner"},
 				Headers:         []string{"Header1", "Header2"},
 				Metadata:        map[string]string{"name": "value"},
 				Spans:           [][]string{{"1", "2"}, {"3"}},
@@ -343,6 +444,21 @@ func TestSimpleRequestResponse(t *testing.T) {
 	}
 }
 
+func testSimpleEventSetQualityOfService(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		message SimpleEvent
+	)
+
+	assert.Nil(message.QualityOfService)
+	assert.True(&message == message.SetQualityOfService(MaxQualityOfService))
+	assert.NotNil(message.QualityOfService)
+	assert.Equal(int64(MaxQualityOfService), *message.QualityOfService)
+	assert.True(&message == message.SetQualityOfService(0))
+	assert.NotNil(message.QualityOfService)
+	assert.Equal(int64(0), *message.QualityOfService)
+}
+
 func testSimpleEventRoutable(t *testing.T, original SimpleEvent) {
 	var (
 		assert  = assert.New(t)
@@ -385,6 +501,8 @@ func testSimpleEventEncode(t *testing.T, f Format, original SimpleEvent) {
 }
 
 func TestSimpleEvent(t *testing.T) {
+	t.Run("SetQualityOfService", testSimpleEventSetQualityOfService)
+
 	var messages = []SimpleEvent{
 		{},
 		{
@@ -396,6 +514,7 @@ func TestSimpleEvent(t *testing.T) {
 			Source:      "mac:123123123123123123",
 			Destination: "something.webpa.comcast.net:9090/here/is/a/path",
 			ContentType: "text/plain",
+			PartnerIDs:  []string{"comcast"},
 			Headers:     []string{"header1"},
 			Metadata:    map[string]string{"a": "b", "c": "d"},
 			Payload:     []byte("check this out!"),
@@ -462,6 +581,36 @@ func testCRUDSetIncludeSpans(t *testing.T) {
 	assert.Equal(false, *message.IncludeSpans)
 }
 
+func testCRUDConstructors(t *testing.T) {
+	testData := []struct {
+		constructor  func(string) (*CRUD, error)
+		expectedType MessageType
+	}{
+		{NewCreate, CreateMessageType},
+		{NewRetrieve, RetrieveMessageType},
+		{NewUpdate, UpdateMessageType},
+		{NewDelete, DeleteMessageType},
+	}
+
+	for _, record := range testData {
+		t.Run(record.expectedType.FriendlyName(), func(t *testing.T) {
+			var (
+				assert  = assert.New(t)
+				require = require.New(t)
+			)
+
+			message, err := record.constructor("/some/path")
+			require.NotNil(message)
+			assert.NoError(err)
+			assert.Equal(CRUD{Type: record.expectedType, Path: "/some/path"}, *message)
+
+			message, err = record.constructor("")
+			assert.Nil(message)
+			assert.Equal(ErrCRUDMissingPath, err)
+		})
+	}
+}
+
 func testCRUDRoutable(t *testing.T, original CRUD) {
 	var (
 		assert  = assert.New(t)
@@ -510,6 +659,7 @@ func TestCRUD(t *testing.T) {
 	t.Run("SetStatus", testCRUDSetStatus)
 	t.Run("SetRequestDeliveryResponse", testCRUDSetRequestDeliveryResponse)
 	t.Run("SetIncludeSpans", testCRUDSetIncludeSpans)
+	t.Run("Constructors", testCRUDConstructors)
 
 	var (
 		expectedStatus                  int64 = -273
@@ -542,6 +692,7 @@ func TestCRUD(t *testing.T) {
 				Source:          "external.com",
 				Destination:     "mac:FFEEAADD44443333",
 				TransactionUUID: "DEADBEEF",
+				PartnerIDs:      []string{"comcast", "partner"},
 				Headers:         []string{"Header1", "Header2"},
 				Metadata:        map[string]string{"name": "value"},
 				Spans:           [][]string{{"1", "2"}, {"3"}},
@@ -628,3 +779,75 @@ func TestServiceAlive(t *testing.T) {
 		})
 	}
 }
+
+func TestPartnerIDsOmitEmpty(t *testing.T) {
+	for _, f := range allFormats {
+		var (
+			assert  = assert.New(t)
+			require = require.New(t)
+			encoded []byte
+			decoded Message
+		)
+
+		// partner_ids is tagged omitempty, so an empty slice is not encoded and decodes as nil
+		require.NoError(NewEncoderBytes(&encoded, f).Encode(&Message{Type: SimpleEventMessageType, PartnerIDs: []string{}}))
+		assert.NotContains(string(encoded), "partner_ids")
+		require.NoError(NewDecoderBytes(encoded, f).Decode(&decoded))
+		assert.Nil(decoded.PartnerIDs)
+	}
+}
+
+func testQualityOfServiceEncode(t *testing.T, f Format, value *int64) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+	)
+
+	for _, original := range []interface{}{
+		&Message{Type: SimpleEventMessageType, QualityOfService: value},
+		&SimpleRequestResponse{Source: "dns:foo.com", Destination: "mac:112233445566", QualityOfService: value},
+		&SimpleEvent{Source: "dns:foo.com", Destination: "event:foo", QualityOfService: value},
+	} {
+		var encoded []byte
+		require.NoError(NewEncoderBytes(&encoded, f).Encode(original))
+		if value == nil {
+			assert.NotContains(string(encoded), "qos")
+		}
+
+		decoded := reflect.New(reflect.TypeOf(original).Elem()).Interface()
+		require.NoError(NewDecoderBytes(encoded, f).Decode(decoded))
+		assert.Equal(original, decoded)
+	}
+}
+
+func testQualityOfServiceOutOfRange(t *testing.T, f Format, value int64) {
+	assert := assert.New(t)
+	for _, original := range []interface{}{
+		new(Message).SetQualityOfService(value),
+		new(SimpleRequestResponse).SetQualityOfService(value),
+		new(SimpleEvent).SetQualityOfService(value),
+	} {
+		var encoded []byte
+		err := NewEncoderBytes(&encoded, f).Encode(original)
+		assert.Error(err)
+		assert.Contains(err.Error(), ErrInvalidQualityOfService.Error())
+		assert.Empty(encoded)
+	}
+}
+
+func TestQualityOfService(t *testing.T) {
+	var (
+		zero    int64 = 0
+		maximum int64 = MaxQualityOfService
+	)
+
+	for _, f := range allFormats {
+		t.Run(f.String(), func(t *testing.T) {
+			t.Run("Nil", func(t *testing.T) { testQualityOfServiceEncode(t, f, nil) })
+			t.Run("Zero", func(t *testing.T) { testQualityOfServiceEncode(t, f, &zero) })
+			t.Run("Max", func(t *testing.T) { testQualityOfServiceEncode(t, f, &maximum) })
+			t.Run("Negative", func(t *testing.T) { testQualityOfServiceOutOfRange(t, f, MinQualityOfService-1) })
+			t.Run("TooLarge", func(t *testing.T) { testQualityOfServiceOutOfRange(t, f, MaxQualityOfService+1) })
+		})
+	}
+}
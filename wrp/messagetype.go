@@ -20,7 +20,10 @@ const (
 	DeleteMessageType
 	ServiceRegistrationMessageType
 	ServiceAliveMessageType
-	lastMessageType
+
+	// lastMessageType is one past the largest known MessageType.  It is deliberately untyped,
+	// so that stringer does not treat it as a MessageType.
+	lastMessageType = iota + 2
 
 	AuthStatusAuthorized      = 200
 	AuthStatusUnauthorized    = 401
@@ -28,22 +31,49 @@ const (
 	AuthStatusNotAcceptable   = 406
 )
 
+// messageTypeTraits describes the routing behavior of a MessageType
+type messageTypeTraits struct {
+	supportsTransaction bool
+	requiresResponse    bool
+}
+
+// knownMessageTypes is the table of all known message types.  Adding a new MessageType
+// constant requires adding an entry here, which drives the predicate methods of MessageType.
+var knownMessageTypes = map[MessageType]messageTypeTraits{
+	AuthorizationStatusMessageType:   {supportsTransaction: false, requiresResponse: false},
+	SimpleRequestResponseMessageType: {supportsTransaction: true, requiresResponse: true},
+	SimpleEventMessageType:           {supportsTransaction: false, requiresResponse: false},
+	CreateMessageType:                {supportsTransaction: true, requiresResponse: true},
+	RetrieveMessageType:              {supportsTransaction: true, requiresResponse: true},
+	UpdateMessageType:                {supportsTransaction: true, requiresResponse: true},
+	DeleteMessageType:                {supportsTransaction: true, requiresResponse: true},
+	ServiceRegistrationMessageType:   {supportsTransaction: false, requiresResponse: false},
+	ServiceAliveMessageType:          {supportsTransaction: false, requiresResponse: false},
+}
+
+// IsKnown tests if this is one of the MessageType constants defined by this package.
+// Unknown types support transactions, as they always have, but do not require responses.
+func (mt MessageType) IsKnown() bool {
+	_, ok := knownMessageTypes[mt]
+	return ok
+}
+
 // SupportsTransaction tests if messages of this type are allowed to participate in transactions.
 // If this method returns false, the TransactionUUID field should be ignored (but passed through
-// where applicable).
+// where applicable).  Types which are not known to this package, including the zero value, are
+// assumed to support transactions.
 func (mt MessageType) SupportsTransaction() bool {
-	switch mt {
-	case AuthorizationStatusMessageType:
-		return false
-	case SimpleEventMessageType:
-		return false
-	case ServiceRegistrationMessageType:
-		return false
-	case ServiceAliveMessageType:
-		return false
-	default:
-		return true
+	if traits, ok := knownMessageTypes[mt]; ok {
+		return traits.supportsTransaction
 	}
+
+	return true
+}
+
+// RequiresResponse tests if messages of this type expect a response, which is correlated with
+// the original message through its TransactionUUID.
+func (mt MessageType) RequiresResponse() bool {
+	return knownMessageTypes[mt].requiresResponse
 }
 
 // FriendlyName is just the String version of this type minus the "MessageType" suffix.
@@ -2,16 +2,31 @@
 
 package wrp
 
-import "fmt"
+import "strconv"
 
-const _MessageType_name = "AuthorizationStatusMessageTypeSimpleRequestResponseMessageTypeSimpleEventMessageTypeCreateMessageTypeRetrieveMessageTypeUpdateMessageTypeDeleteMessageTypeServiceRegistrationMessageTypeServiceAliveMessageTypelastMessageType"
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[AuthorizationStatusMessageType-2]
+	_ = x[SimpleRequestResponseMessageType-3]
+	_ = x[SimpleEventMessageType-4]
+	_ = x[CreateMessageType-5]
+	_ = x[RetrieveMessageType-6]
+	_ = x[UpdateMessageType-7]
+	_ = x[DeleteMessageType-8]
+	_ = x[ServiceRegistrationMessageType-9]
+	_ = x[ServiceAliveMessageType-10]
+}
+
+const _MessageType_name = "AuthorizationStatusMessageTypeSimpleRequestResponseMessageTypeSimpleEventMessageTypeCreateMessageTypeRetrieveMessageTypeUpdateMessageTypeDeleteMessageTypeServiceRegistrationMessageTypeServiceAliveMessageType"
 
-var _MessageType_index = [...]uint8{0, 30, 62, 84, 101, 120, 137, 154, 184, 207, 222}
+var _MessageType_index = [...]uint8{0, 30, 62, 84, 101, 120, 137, 154, 184, 207}
 
 func (i MessageType) String() string {
-	i -= 2
-	if i < 0 || i >= MessageType(len(_MessageType_index)-1) {
-		return fmt.Sprintf("MessageType(%d)", i+2)
+	idx := int(i) - 2
+	if i < 2 || idx >= len(_MessageType_index)-1 {
+		return "MessageType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _MessageType_name[_MessageType_index[i]:_MessageType_index[i+1]]
+	return _MessageType_name[_MessageType_index[idx]:_MessageType_index[idx+1]]
 }
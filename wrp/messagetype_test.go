@@ -2,6 +2,7 @@ package wrp
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,6 +57,54 @@ func TestMessageTypeSupportsTransaction(t *testing.T) {
 	for messageType, expected := range expectedSupportsTransaction {
 		assert.Equal(expected, messageType.SupportsTransaction())
 	}
+
+	// unknown types have always been treated as transactional
+	assert.True(MessageType(-1).SupportsTransaction())
+	assert.True(MessageType(0).SupportsTransaction())
+	assert.True(MessageType(lastMessageType).SupportsTransaction())
+}
+
+func TestMessageTypeRequiresResponse(t *testing.T) {
+	var (
+		assert                   = assert.New(t)
+		expectedRequiresResponse = map[MessageType]bool{
+			AuthorizationStatusMessageType:   false,
+			SimpleRequestResponseMessageType: true,
+			SimpleEventMessageType:           false,
+			CreateMessageType:                true,
+			RetrieveMessageType:              true,
+			UpdateMessageType:                true,
+			DeleteMessageType:                true,
+			ServiceRegistrationMessageType:   false,
+			ServiceAliveMessageType:          false,
+		}
+	)
+
+	for messageType, expected := range expectedRequiresResponse {
+		assert.Equal(expected, messageType.RequiresResponse())
+	}
+
+	assert.False(MessageType(-1).RequiresResponse())
+	assert.False(MessageType(lastMessageType).RequiresResponse())
+}
+
+func TestMessageTypeIsKnown(t *testing.T) {
+	assert := assert.New(t)
+
+	// the String values and the table of known types must agree
+	for messageType := MessageType(-1); messageType <= lastMessageType+1; messageType++ {
+		assert.Equal(
+			messageType.IsKnown(),
+			!strings.HasPrefix(messageType.String(), "MessageType("),
+			"IsKnown and String disagree for %s",
+			messageType,
+		)
+	}
+
+	assert.False(MessageType(lastMessageType).IsKnown())
+	assert.False(MessageType(lastMessageType + 1).IsKnown())
+	assert.Equal("MessageType(11)", MessageType(lastMessageType).String())
+	assert.Len(knownMessageTypes, int(lastMessageType-AuthorizationStatusMessageType))
 }
 
 func testStringToMessageTypeValid(t *testing.T, expected MessageType) {
@@ -0,0 +1,84 @@
+package wrp
+
+// MergeMetadata merges the given values into this message's Metadata, allocating the Metadata map
+// if necessary.  When overwrite is true, values replace any existing entries with the same keys.
+// Otherwise, existing entries are preserved.  This method returns this message for chaining.
+func (msg *Message) MergeMetadata(values map[string]string, overwrite bool) *Message {
+	if len(values) == 0 {
+		return msg
+	}
+
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]string, len(values))
+	}
+
+	for key, value := range values {
+		if _, exists := msg.Metadata[key]; overwrite || !exists {
+			msg.Metadata[key] = value
+		}
+	}
+
+	return msg
+}
+
+// MetadataBuilder accumulates changes to WRP metadata so that several layers of code can each contribute
+// keys before the changes are applied to a Message.  Changes are applied in the order they were made.
+//
+// The zero value of a MetadataBuilder is ready to use.  A MetadataBuilder is not safe for concurrent use.
+type MetadataBuilder struct {
+	changes []func(map[string]string)
+}
+
+// Set records a change that sets the given key, replacing any existing value
+func (mb *MetadataBuilder) Set(key, value string) *MetadataBuilder {
+	mb.changes = append(mb.changes, func(metadata map[string]string) {
+		metadata[key] = value
+	})
+
+	return mb
+}
+
+// SetIfAbsent records a change that sets the given key only if the Message, at the time this builder
+// is applied, does not already have a value for that key
+func (mb *MetadataBuilder) SetIfAbsent(key, value string) *MetadataBuilder {
+	mb.changes = append(mb.changes, func(metadata map[string]string) {
+		if _, exists := metadata[key]; !exists {
+			metadata[key] = value
+		}
+	})
+
+	return mb
+}
+
+// Merge records a change that merges all the given values, with the same semantics as Message.MergeMetadata.
+// The values are copied, so later changes to the given map do not affect this builder.
+func (mb *MetadataBuilder) Merge(values map[string]string, overwrite bool) *MetadataBuilder {
+	for key, value := range values {
+		if overwrite {
+			mb.Set(key, value)
+		} else {
+			mb.SetIfAbsent(key, value)
+		}
+	}
+
+	return mb
+}
+
+// Apply makes each recorded change to the given Message's Metadata, allocating the Metadata map
+// if necessary.  If no changes have been recorded, the Message is left untouched.  This builder
+// may be applied to any number of messages.
+func (mb *MetadataBuilder) Apply(msg *Message) *Message {
+	if len(mb.changes) == 0 {
+		return msg
+	}
+
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]string)
+	}
+
+	for _, change := range mb.changes {
+		change(msg.Metadata)
+	}
+
+	return msg
+}
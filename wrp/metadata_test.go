@@ -0,0 +1,117 @@
+package wrp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessageMergeMetadataNil(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		message Message
+	)
+
+	assert.True(&message == message.MergeMetadata(nil, true))
+	assert.Nil(message.Metadata)
+
+	assert.True(&message == message.MergeMetadata(map[string]string{"a": "1"}, false))
+	assert.Equal(map[string]string{"a": "1"}, message.Metadata)
+}
+
+func testMessageMergeMetadataOverwrite(t *testing.T, overwrite bool, expected map[string]string) {
+	var (
+		assert  = assert.New(t)
+		values  = map[string]string{"a": "new", "c": "3"}
+		message = Message{Metadata: map[string]string{"a": "1", "b": "2"}}
+	)
+
+	message.MergeMetadata(values, overwrite)
+	assert.Equal(expected, message.Metadata)
+	assert.Equal(map[string]string{"a": "new", "c": "3"}, values)
+}
+
+func TestMessageMergeMetadata(t *testing.T) {
+	t.Run("Nil", testMessageMergeMetadataNil)
+	t.Run("Overwrite", func(t *testing.T) {
+		testMessageMergeMetadataOverwrite(t, true, map[string]string{"a": "new", "b": "2", "c": "3"})
+	})
+
+	t.Run("Preserve", func(t *testing.T) {
+		testMessageMergeMetadataOverwrite(t, false, map[string]string{"a": "1", "b": "2", "c": "3"})
+	})
+}
+
+func testMetadataBuilderEmpty(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		builder MetadataBuilder
+		message Message
+	)
+
+	assert.True(&message == builder.Apply(&message))
+	assert.Nil(message.Metadata)
+}
+
+func testMetadataBuilderApply(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		merged = map[string]string{"b": "merged", "c": "merged", "d": "merged"}
+
+		builder = new(MetadataBuilder).
+			Set("a", "set").
+			SetIfAbsent("b", "ifAbsent").
+			SetIfAbsent("e", "ifAbsent").
+			Merge(merged, false).
+			Merge(map[string]string{"d": "overwritten"}, true)
+
+		withNil      Message
+		withExisting = Message{Metadata: map[string]string{"a": "existing", "b": "existing", "f": "existing"}}
+	)
+
+	// changes to the merged map after the fact must not affect the builder
+	merged["c"] = "changed"
+
+	assert.True(&withNil == builder.Apply(&withNil))
+	assert.Equal(
+		map[string]string{"a": "set", "b": "ifAbsent", "c": "merged", "d": "overwritten", "e": "ifAbsent"},
+		withNil.Metadata,
+	)
+
+	builder.Apply(&withExisting)
+	assert.Equal(
+		map[string]string{"a": "set", "b": "existing", "c": "merged", "d": "overwritten", "e": "ifAbsent", "f": "existing"},
+		withExisting.Metadata,
+	)
+}
+
+func testMetadataBuilderEncode(t *testing.T) {
+	var (
+		message = new(MetadataBuilder).
+			Set("first", "1").
+			Merge(map[string]string{"second": "2", "third": "3"}, false).
+			Apply(&Message{Type: SimpleEventMessageType, Source: "dns:foo.com", Destination: "event:foo"})
+	)
+
+	for _, f := range allFormats {
+		t.Run(f.String(), func(t *testing.T) {
+			var (
+				assert  = assert.New(t)
+				require = require.New(t)
+				encoded []byte
+				decoded Message
+			)
+
+			require.NoError(NewEncoderBytes(&encoded, f).Encode(message))
+			require.NoError(NewDecoderBytes(encoded, f).Decode(&decoded))
+			assert.Equal(*message, decoded)
+		})
+	}
+}
+
+func TestMetadataBuilder(t *testing.T) {
+	t.Run("Empty", testMetadataBuilderEmpty)
+	t.Run("Apply", testMetadataBuilderApply)
+	t.Run("Encode", testMetadataBuilderEncode)
+}
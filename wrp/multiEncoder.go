@@ -0,0 +1,58 @@
+package wrp
+
+// MultiEncoder encodes messages into several formats at once.  Each format is backed by its own
+// EncoderPool, so a MultiEncoder is safe for concurrent use.
+type MultiEncoder struct {
+	formats []Format
+	pools   []*EncoderPool
+}
+
+// NewMultiEncoder creates a MultiEncoder for the given formats, each of which uses a pool with the
+// given capacity.  If no formats are supplied, AllFormats is used.  Duplicate formats are ignored.
+func NewMultiEncoder(capacity int, formats ...Format) *MultiEncoder {
+	if len(formats) == 0 {
+		formats = AllFormats()
+	}
+
+	me := new(MultiEncoder)
+	for _, f := range formats {
+		if me.Pool(f) == nil {
+			me.formats = append(me.formats, f)
+			me.pools = append(me.pools, NewEncoderPool(capacity, f))
+		}
+	}
+
+	return me
+}
+
+// Formats returns the formats this MultiEncoder produces, in the order they were supplied
+func (me *MultiEncoder) Formats() []Format {
+	return append([]Format(nil), me.formats...)
+}
+
+// Pool returns the EncoderPool for the given format, or nil if this MultiEncoder does not support that format
+func (me *MultiEncoder) Pool(f Format) *EncoderPool {
+	for i, candidate := range me.formats {
+		if candidate == f {
+			return me.pools[i]
+		}
+	}
+
+	return nil
+}
+
+// EncodeAll encodes the source into each of this MultiEncoder's formats.  If any format fails to encode,
+// that error is returned along with a nil map.
+func (me *MultiEncoder) EncodeAll(source interface{}) (map[Format][]byte, error) {
+	encoded := make(map[Format][]byte, len(me.formats))
+	for i, f := range me.formats {
+		var output []byte
+		if err := me.pools[i].EncodeBytes(&output, source); err != nil {
+			return nil, err
+		}
+
+		encoded[f] = output
+	}
+
+	return encoded, nil
+}
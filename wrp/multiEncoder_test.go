@@ -0,0 +1,114 @@
+package wrp
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiEncoder(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		message = &Message{
+			Type:        SimpleEventMessageType,
+			Source:      "test",
+			Destination: "mac:123412341234",
+			ContentType: "text/plain",
+			Payload:     []byte("multi encoder test"),
+		}
+	)
+
+	t.Run("AllFormats", func(t *testing.T) {
+		multiEncoder := NewMultiEncoder(2)
+		assert.Equal(AllFormats(), multiEncoder.Formats())
+
+		encoded, err := multiEncoder.EncodeAll(message)
+		require.NoError(err)
+		require.Len(encoded, len(AllFormats()))
+
+		for _, f := range AllFormats() {
+			var expected []byte
+			require.NoError(NewEncoderBytes(&expected, f).Encode(message))
+			assert.Equal(expected, encoded[f], "Incorrect encoding for format %s", f)
+
+			actual := new(Message)
+			require.NoError(NewDecoderBytes(encoded[f], f).Decode(actual))
+			assert.Equal(message, actual)
+
+			if assert.NotNil(multiEncoder.Pool(f)) {
+				assert.Equal(f, multiEncoder.Pool(f).Format())
+				assert.Equal(2, multiEncoder.Pool(f).Cap())
+			}
+		}
+	})
+
+	t.Run("SomeFormats", func(t *testing.T) {
+		multiEncoder := NewMultiEncoder(0, JSON, JSON)
+		assert.Equal([]Format{JSON}, multiEncoder.Formats())
+		assert.Nil(multiEncoder.Pool(Msgpack))
+
+		encoded, err := multiEncoder.EncodeAll(message)
+		require.NoError(err)
+		assert.Len(encoded, 1)
+		assert.Contains(encoded, JSON)
+	})
+
+	t.Run("EncodeError", func(t *testing.T) {
+		encoded, err := NewMultiEncoder(0).EncodeAll(complex(1, 2))
+		assert.Nil(encoded)
+		assert.Error(err)
+	})
+}
+
+func BenchmarkMultiEncoder(b *testing.B) {
+	payload := make([]byte, 1024)
+	rand.Read(payload)
+
+	var (
+		message = &Message{
+			Type:        SimpleRequestResponseMessageType,
+			Source:      "test",
+			Destination: "mac:123412341234",
+			ContentType: "application/octet-stream",
+			Payload:     payload,
+		}
+
+		multiEncoder = NewMultiEncoder(10)
+		encoderPools = make(map[Format]*EncoderPool)
+	)
+
+	for _, f := range AllFormats() {
+		encoderPools[f] = NewEncoderPool(10, f)
+	}
+
+	b.ResetTimer()
+	b.Run("MultiEncoder", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := multiEncoder.EncodeAll(message); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+
+	b.Run("EncoderPools", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				encoded := make(map[Format][]byte, len(encoderPools))
+				for f, pool := range encoderPools {
+					var output []byte
+					if err := pool.EncodeBytes(&output, message); err != nil {
+						b.Fatal(err)
+					}
+
+					encoded[f] = output
+				}
+			}
+		})
+	})
+}
@@ -7,6 +7,11 @@ import (
 
 const (
 	DefaultPoolCapacity = 100
+
+	// maxPooledBufferSize is the largest capacity of a scratch buffer that is returned to an EncoderPool.
+	// Larger buffers, grown by the occasional very large message, are left for the garbage collector
+	// rather than pinning that memory for the life of the pool.
+	maxPooledBufferSize = 64 * 1024
 )
 
 // EncoderPool represents a pool of Encoder objects that can be used as is
@@ -15,6 +20,7 @@ const (
 type EncoderPool struct {
 	lock     sync.Mutex
 	pool     []Encoder
+	buffers  []*[]byte
 	capacity int
 	format   Format
 }
@@ -29,6 +35,7 @@ func NewEncoderPool(capacity int, f Format) *EncoderPool {
 
 	return &EncoderPool{
 		pool:     make([]Encoder, 0, capacity),
+		buffers:  make([]*[]byte, 0, capacity),
 		capacity: capacity,
 		format:   f,
 	}
@@ -93,13 +100,59 @@ func (ep *EncoderPool) Put(encoder Encoder) (returned bool) {
 	return
 }
 
-// Encode uses an Encoder from the pool to encode the source into the destination
+// getBuffer returns a scratch buffer from the pool, creating an empty one if none are available
+func (ep *EncoderPool) getBuffer() (buffer *[]byte) {
+	ep.lock.Lock()
+
+	last := len(ep.buffers) - 1
+	if last >= 0 {
+		buffer, ep.buffers[last] = ep.buffers[last], nil
+		ep.buffers = ep.buffers[0:last]
+	} else {
+		buffer = new([]byte)
+	}
+
+	ep.lock.Unlock()
+	return
+}
+
+// putBuffer returns a scratch buffer to the pool, subject to the pool's capacity.  Buffers
+// larger than maxPooledBufferSize are dropped.
+func (ep *EncoderPool) putBuffer(buffer *[]byte) {
+	if cap(*buffer) > maxPooledBufferSize {
+		return
+	}
+
+	*buffer = (*buffer)[:0]
+	ep.lock.Lock()
+
+	if len(ep.buffers) < ep.capacity {
+		ep.buffers = append(ep.buffers, buffer)
+	}
+
+	ep.lock.Unlock()
+}
+
+// Encode uses an Encoder from the pool to encode the source into the destination.  The source
+// is encoded into a pooled scratch buffer, which is then written to the destination with exactly
+// (1) call to Write.  Once the pool's scratch buffers have grown to the size of typical messages,
+// encoding does not allocate.
 func (ep *EncoderPool) Encode(destination io.Writer, source interface{}) error {
-	encoder := ep.Get()
+	var (
+		encoder = ep.Get()
+		buffer  = ep.getBuffer()
+	)
+
+	defer ep.putBuffer(buffer)
 	defer ep.Put(encoder)
 
-	encoder.Reset(destination)
-	return encoder.Encode(source)
+	encoder.ResetBytes(buffer)
+	if err := encoder.Encode(source); err != nil {
+		return err
+	}
+
+	_, err := destination.Write(*buffer)
+	return err
 }
 
 // EncodeBytes uses an encoder from the pool to encode the source into a byte array.
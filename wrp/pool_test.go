@@ -50,7 +50,7 @@ func testEncoderPoolEncode(t *testing.T, ep *EncoderPool, dp *DecoderPool) {
 		assert  = assert.New(t)
 		require = require.New(t)
 
-		input  = &Message{Payload: []byte("hi!"), Source: "test"}
+		input  = &Message{Payload: []byte("hi!"), Source: "test", PartnerIDs: []string{"comcast", "partner"}}
 		output = new(bytes.Buffer)
 
 		decoded = new(Message)
@@ -62,12 +62,64 @@ func testEncoderPoolEncode(t *testing.T, ep *EncoderPool, dp *DecoderPool) {
 	assert.Equal(*input, *decoded)
 }
 
+// countingWriter records each call to Write
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.writes++
+	return cw.Buffer.Write(p)
+}
+
+func testEncoderPoolEncodeScratchBuffers(t *testing.T, ep *EncoderPool, dp *DecoderPool) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		inputs = []*Message{
+			{Payload: []byte("a somewhat larger payload, so that the scratch buffer must grow"), Source: "test"},
+			{Payload: []byte("hi!"), Source: "test"},
+		}
+	)
+
+	for _, input := range inputs {
+		var (
+			output  = new(countingWriter)
+			decoded = new(Message)
+		)
+
+		require.NoError(ep.Encode(output, input))
+		assert.Equal(1, output.writes)
+		assert.NoError(dp.Decode(decoded, output))
+		assert.Equal(*input, *decoded)
+	}
+
+	assert.Equal(1, len(ep.buffers))
+	assert.Equal(1, ep.Len())
+	assert.Error(ep.Encode(new(bytes.Buffer), complex(1, 2)))
+	assert.Equal(1, len(ep.buffers))
+
+	// a buffer grown past maxPooledBufferSize is not returned to the pool
+	var (
+		large   = &Message{Payload: make([]byte, 2*maxPooledBufferSize), Source: "test"}
+		output  = new(bytes.Buffer)
+		decoded = new(Message)
+	)
+
+	require.NoError(ep.Encode(output, large))
+	assert.NoError(dp.Decode(decoded, output))
+	assert.Equal(*large, *decoded)
+	assert.Empty(ep.buffers)
+}
+
 func testEncoderPoolEncodeBytes(t *testing.T, ep *EncoderPool, dp *DecoderPool) {
 	var (
 		assert  = assert.New(t)
 		require = require.New(t)
 
-		input  = &Message{Payload: []byte("hi!"), Source: "test"}
+		input  = &Message{Payload: []byte("hi!"), Source: "test", PartnerIDs: []string{"comcast", "partner"}}
 		output []byte
 
 		decoded = new(Message)
@@ -96,6 +148,10 @@ func TestEncoderPool(t *testing.T) {
 						testEncoderPoolEncode(t, NewEncoderPool(c, f), NewDecoderPool(c, f))
 					})
 
+					t.Run("EncodeScratchBuffers", func(t *testing.T) {
+						testEncoderPoolEncodeScratchBuffers(t, NewEncoderPool(c, f), NewDecoderPool(c, f))
+					})
+
 					t.Run("EncodeBytes", func(t *testing.T) {
 						testEncoderPoolEncodeBytes(t, NewEncoderPool(c, f), NewDecoderPool(c, f))
 					})
@@ -268,3 +324,141 @@ func benchmarkDecoder(b *testing.B, format Format, data []byte) {
 		}
 	})
 }
+
+// BenchmarkEncoderPoolEncode compares encoding into an io.Writer through EncoderPool.Encode,
+// which uses pooled scratch buffers, with resetting a pooled Encoder directly onto the io.Writer.
+// It also includes the single goroutine case of one unpooled Encoder reset onto each io.Writer.
+func BenchmarkEncoderPoolEncode(b *testing.B) {
+	payload := make([]byte, 1024)
+	rand.Read(payload)
+
+	message := &Message{
+		Type:        SimpleEventMessageType,
+		Source:      "test",
+		Destination: "mac:123412341234",
+		Payload:     payload,
+	}
+
+	for _, f := range AllFormats() {
+		b.Run(f.String(), func(b *testing.B) {
+			b.Run("ScratchBuffer", func(b *testing.B) {
+				var (
+					pool   = NewEncoderPool(10, f)
+					output = new(bytes.Buffer)
+				)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					output.Reset()
+					if err := pool.Encode(output, message); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run("ResetWriter", func(b *testing.B) {
+				var (
+					pool   = NewEncoderPool(10, f)
+					output = new(bytes.Buffer)
+				)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					output.Reset()
+					encoder := pool.Get()
+					encoder.Reset(output)
+					if err := encoder.Encode(message); err != nil {
+						b.Fatal(err)
+					}
+
+					pool.Put(encoder)
+				}
+			})
+
+			b.Run("Reused", func(b *testing.B) {
+				var (
+					encoder = NewEncoder(nil, f)
+					outputs = []*bytes.Buffer{new(bytes.Buffer), new(bytes.Buffer)}
+				)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					output := outputs[i%len(outputs)]
+					output.Reset()
+					encoder.Reset(output)
+					if err := encoder.Encode(message); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkDecoderReset compares creating a Decoder for each input with resetting a single Decoder,
+// as returned by NewDecoder, onto each input.
+func BenchmarkDecoderReset(b *testing.B) {
+	payload := make([]byte, 1024)
+	rand.Read(payload)
+
+	message := &Message{
+		Type:        SimpleEventMessageType,
+		Source:      "test",
+		Destination: "mac:123412341234",
+		Payload:     payload,
+	}
+
+	for _, f := range AllFormats() {
+		data := MustEncode(message, f)
+		b.Run(f.String(), func(b *testing.B) {
+			b.Run("New", func(b *testing.B) {
+				input := bytes.NewReader(data)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					var decoded Message
+					input.Reset(data)
+					if err := NewDecoder(input, f).Decode(&decoded); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run("Reset", func(b *testing.B) {
+				var (
+					decoder = NewDecoder(nil, f)
+					input   = bytes.NewReader(data)
+				)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					var decoded Message
+					input.Reset(data)
+					decoder.Reset(input)
+					if err := decoder.Decode(&decoded); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run("ResetBytes", func(b *testing.B) {
+				decoder := NewDecoder(nil, f)
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					var decoded Message
+					decoder.ResetBytes(data)
+					if err := decoder.Decode(&decoded); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
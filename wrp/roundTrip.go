@@ -0,0 +1,95 @@
+package wrp
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RoundTripError describes a Message field that did not survive a round trip through a Format
+type RoundTripError struct {
+	// Format is the format the message was encoded to and decoded from
+	Format Format
+
+	// Field is the name of the first Message field that differed after the round trip
+	Field string
+
+	// Expected is the field's original value
+	Expected interface{}
+
+	// Actual is the field's value after the round trip
+	Actual interface{}
+}
+
+func (rte *RoundTripError) Error() string {
+	return fmt.Sprintf("Field %s did not survive a round trip through %s: expected %#v, got %#v", rte.Field, rte.Format, rte.Expected, rte.Actual)
+}
+
+// RoundTrip encodes the given message in the given format, then decodes the result into a new Message
+func RoundTrip(msg *Message, f Format) (*Message, error) {
+	var encoded []byte
+	if err := NewEncoderBytes(&encoded, f).Encode(msg); err != nil {
+		return nil, err
+	}
+
+	decoded := new(Message)
+	if err := NewDecoderBytes(encoded, f).Decode(decoded); err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// CheckRoundTrip round trips the given message through every format returned by AllFormats.  If any field
+// differs afterward, a *RoundTripError naming the first such field is returned.  Since encoding omits empty
+// fields, nil and empty slices or maps are considered equal.
+//
+// This function is primarily useful in tests, to verify that every field of a message is transcoded.
+func CheckRoundTrip(msg *Message) error {
+	for _, f := range AllFormats() {
+		decoded, err := RoundTrip(msg, f)
+		if err != nil {
+			return err
+		}
+
+		if field, expected, actual, ok := firstDifference(msg, decoded); ok {
+			return &RoundTripError{
+				Format:   f,
+				Field:    field,
+				Expected: expected,
+				Actual:   actual,
+			}
+		}
+	}
+
+	return nil
+}
+
+// firstDifference compares each field of two messages, returning the name and values of the first
+// field that differs
+func firstDifference(expected, actual *Message) (string, interface{}, interface{}, bool) {
+	var (
+		expectedValue = reflect.ValueOf(expected).Elem()
+		actualValue   = reflect.ValueOf(actual).Elem()
+		messageType   = expectedValue.Type()
+	)
+
+	for i := 0; i < messageType.NumField(); i++ {
+		var (
+			expectedField = expectedValue.Field(i)
+			actualField   = actualValue.Field(i)
+		)
+
+		switch expectedField.Kind() {
+		case reflect.Slice, reflect.Map:
+			if expectedField.Len() == 0 && actualField.Len() == 0 {
+				continue
+			}
+		}
+
+		if !reflect.DeepEqual(expectedField.Interface(), actualField.Interface()) {
+			return messageType.Field(i).Name, expectedField.Interface(), actualField.Interface(), true
+		}
+	}
+
+	return "", nil, nil, false
+}
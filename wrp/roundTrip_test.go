@@ -0,0 +1,93 @@
+package wrp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRoundTrip(t *testing.T, f Format) {
+	var (
+		assert   = assert.New(t)
+		require  = require.New(t)
+		original = new(Message).
+				SetStatus(200).
+				SetRequestDeliveryResponse(1).
+				SetIncludeSpans(true).
+				SetQualityOfService(50)
+	)
+
+	original.Type = SimpleRequestResponseMessageType
+	original.Source = "dns:server.com"
+	original.Destination = "mac:112233445566"
+	original.TransactionUUID = "1234"
+	original.Metadata = map[string]string{"key": "value"}
+	original.Spans = [][]string{{"name", "2017-06-14T10:30:00Z", "1s"}}
+	original.Payload = []byte{0x00, 0x01, 0xFF}
+
+	decoded, err := RoundTrip(original, f)
+	require.NoError(err)
+	assert.Equal(original, decoded)
+
+	decoded, err = RoundTrip(new(Message).SetQualityOfService(MaxQualityOfService+1), f)
+	assert.Nil(decoded)
+	assert.Error(err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, f := range AllFormats() {
+		t.Run(f.String(), func(t *testing.T) { testRoundTrip(t, f) })
+	}
+}
+
+func testCheckRoundTripSuccess(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(CheckRoundTrip(new(Message)))
+	assert.NoError(CheckRoundTrip(&Message{
+		Type:        SimpleEventMessageType,
+		Source:      "mac:112233445566",
+		Destination: "event:device-status",
+		PartnerIDs:  []string{},
+		Metadata:    map[string]string{},
+		Payload:     []byte{},
+	}))
+}
+
+func testCheckRoundTripError(t *testing.T) {
+	var (
+		assert  = assert.New(t)
+		message = new(Message).SetQualityOfService(MaxQualityOfService + 1)
+	)
+
+	assert.Error(CheckRoundTrip(message))
+}
+
+func testFirstDifference(t *testing.T) {
+	var (
+		assert   = assert.New(t)
+		expected = &Message{Source: "mac:112233445566", Headers: []string{"a"}, Payload: []byte{}}
+		actual   = &Message{Source: "mac:112233445566", Headers: []string{"b"}}
+	)
+
+	field, expectedValue, actualValue, ok := firstDifference(expected, actual)
+	assert.True(ok)
+	assert.Equal("Headers", field)
+	assert.Equal([]string{"a"}, expectedValue)
+	assert.Equal([]string{"b"}, actualValue)
+
+	actual.Headers = []string{"a"}
+	_, _, _, ok = firstDifference(expected, actual)
+	assert.False(ok)
+
+	err := &RoundTripError{Format: JSON, Field: field, Expected: expectedValue, Actual: actualValue}
+	assert.Contains(err.Error(), "Headers")
+	assert.Contains(err.Error(), JSON.String())
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	t.Run("Success", testCheckRoundTripSuccess)
+	t.Run("Error", testCheckRoundTripError)
+	t.Run("FirstDifference", testFirstDifference)
+}
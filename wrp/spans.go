@@ -0,0 +1,130 @@
+package wrp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Comcast/webpa-common/tracing"
+)
+
+// The columns of each row in Message.Spans.  The error column is only present when the span has an error.
+const (
+	SpanNameColumn = iota
+	SpanStartColumn
+	SpanDurationColumn
+	SpanErrorColumn
+)
+
+// SpanFormatter converts between tracing.Span values and the rows of Message.Spans.  Each row
+// holds the span's name, its start time in UTC, its duration as produced by time.Duration.String,
+// and, only if the span has an error, the error text.
+//
+// The zero value of a SpanFormatter is ready to use.
+type SpanFormatter struct {
+	// TimeLayout is the layout used for span start times.  If unset, time.RFC3339Nano is used.
+	TimeLayout string
+}
+
+func (sf SpanFormatter) timeLayout() string {
+	if len(sf.TimeLayout) > 0 {
+		return sf.TimeLayout
+	}
+
+	return time.RFC3339Nano
+}
+
+// Format produces a row for each of the given spans, in order
+func (sf SpanFormatter) Format(spans ...tracing.Span) [][]string {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	var (
+		timeLayout = sf.timeLayout()
+		rows       = make([][]string, 0, len(spans))
+	)
+
+	for _, s := range spans {
+		row := []string{
+			s.Name(),
+			s.Start().UTC().Format(timeLayout),
+			s.Duration().String(),
+		}
+
+		if err := s.Error(); err != nil {
+			row = append(row, err.Error())
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// Parse is the inverse of Format.  Errors in the returned spans only preserve the text of the
+// original errors.  An error is returned if any row does not have the expected columns.
+func (sf SpanFormatter) Parse(rows [][]string) ([]tracing.Span, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	var (
+		timeLayout = sf.timeLayout()
+		spans      = make([]tracing.Span, 0, len(rows))
+	)
+
+	for i, row := range rows {
+		if len(row) != SpanErrorColumn && len(row) != SpanErrorColumn+1 {
+			return nil, fmt.Errorf("Span %d has %d columns", i, len(row))
+		}
+
+		start, err := time.Parse(timeLayout, row[SpanStartColumn])
+		if err != nil {
+			return nil, fmt.Errorf("Span %d has an invalid start time: %s", i, err)
+		}
+
+		duration, err := time.ParseDuration(row[SpanDurationColumn])
+		if err != nil {
+			return nil, fmt.Errorf("Span %d has an invalid duration: %s", i, err)
+		}
+
+		s := &parsedSpan{
+			name:     row[SpanNameColumn],
+			start:    start,
+			duration: duration,
+		}
+
+		if len(row) > SpanErrorColumn {
+			s.err = errors.New(row[SpanErrorColumn])
+		}
+
+		spans = append(spans, s)
+	}
+
+	return spans, nil
+}
+
+// parsedSpan is the tracing.Span implementation produced by SpanFormatter.Parse
+type parsedSpan struct {
+	name     string
+	start    time.Time
+	duration time.Duration
+	err      error
+}
+
+func (ps *parsedSpan) Name() string {
+	return ps.name
+}
+
+func (ps *parsedSpan) Start() time.Time {
+	return ps.start
+}
+
+func (ps *parsedSpan) Duration() time.Duration {
+	return ps.duration
+}
+
+func (ps *parsedSpan) Error() error {
+	return ps.err
+}
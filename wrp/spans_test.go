@@ -0,0 +1,110 @@
+package wrp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Comcast/webpa-common/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSpanFormatterEmpty(t *testing.T) {
+	var (
+		assert    = assert.New(t)
+		formatter SpanFormatter
+	)
+
+	assert.Nil(formatter.Format())
+
+	spans, err := formatter.Parse(nil)
+	assert.Nil(spans)
+	assert.NoError(err)
+}
+
+func testSpanFormatterRoundTrip(t *testing.T, formatter SpanFormatter, expectedStart string) {
+	var (
+		assert  = assert.New(t)
+		require = require.New(t)
+
+		start   = time.Date(2017, time.June, 14, 10, 30, 15, 0, time.FixedZone("test", -5*60*60))
+		elapsed = []time.Duration{0, 1500 * time.Millisecond}
+		spanner = tracing.NewSpanner(
+			tracing.Now(func() time.Time { return start }),
+			tracing.Since(func(time.Time) time.Duration {
+				next := elapsed[0]
+				elapsed = elapsed[1:]
+				return next
+			}),
+		)
+
+		original = []tracing.Span{
+			spanner.Start("zero")(nil),
+			spanner.Start("failed")(errors.New("expected")),
+		}
+	)
+
+	rows := formatter.Format(original...)
+	assert.Equal(
+		[][]string{
+			{"zero", expectedStart, "0s"},
+			{"failed", expectedStart, "1.5s", "expected"},
+		},
+		rows,
+	)
+
+	// formatted spans, including those with errors, must pass message validation
+	message := Message{
+		Type:        SimpleEventMessageType,
+		Source:      "dns:talaria.comcast.net",
+		Destination: "event:device-status",
+		Spans:       rows,
+	}
+
+	assert.NoError(message.Validate())
+
+	parsed, err := formatter.Parse(rows)
+	require.NoError(err)
+	require.Len(parsed, len(original))
+	for i, expected := range original {
+		assert.Equal(expected.Name(), parsed[i].Name())
+		assert.True(expected.Start().Equal(parsed[i].Start()))
+		assert.Equal(expected.Duration(), parsed[i].Duration())
+		assert.Equal(expected.Error(), parsed[i].Error())
+	}
+}
+
+func testSpanFormatterParseError(t *testing.T) {
+	var (
+		assert    = assert.New(t)
+		formatter SpanFormatter
+	)
+
+	for _, rows := range [][][]string{
+		{{"name", "2017-06-14T10:30:00Z"}},
+		{{"name", "2017-06-14T10:30:00Z", "1s", "error", "extra"}},
+		{{"name", "this is not a time", "1s"}},
+		{{"name", "2017-06-14T10:30:00Z", "this is not a duration"}},
+		{{"name", "2017-06-14T10:30:00Z", "1s"}, {}},
+	} {
+		spans, err := formatter.Parse(rows)
+		assert.Nil(spans)
+		assert.Error(err)
+	}
+}
+
+func TestSpanFormatter(t *testing.T) {
+	t.Run("Empty", testSpanFormatterEmpty)
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Run("Default", func(t *testing.T) {
+			testSpanFormatterRoundTrip(t, SpanFormatter{}, "2017-06-14T15:30:15Z")
+		})
+
+		t.Run("TimeLayout", func(t *testing.T) {
+			testSpanFormatterRoundTrip(t, SpanFormatter{TimeLayout: time.RFC1123Z}, "Wed, 14 Jun 2017 15:30:15 +0000")
+		})
+	})
+
+	t.Run("ParseError", testSpanFormatterParseError)
+}
@@ -0,0 +1,135 @@
+package wrp
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// FrameHeaderSize is the size of the length prefix of each frame read by ReadFrame and written by WriteFrame.
+	// The prefix is the length of the frame's contents as an unsigned, big-endian 32-bit integer.
+	FrameHeaderSize = 4
+
+	// MaxFrameSize is the largest frame, excluding its length prefix, that ReadFrame accepts
+	MaxFrameSize = 16 * 1024 * 1024
+)
+
+var ErrFrameTooLarge = errors.New("The frame exceeds the maximum frame size")
+
+// WriteFrame writes a length-prefixed frame to the given output
+func WriteFrame(output io.Writer, frame []byte) error {
+	if len(frame) > MaxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	var header [FrameHeaderSize]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(frame)))
+	if _, err := output.Write(header[:]); err != nil {
+		return err
+	}
+
+	_, err := output.Write(frame)
+	return err
+}
+
+// ReadFrame reads the next length-prefixed frame from the given input, reusing the given buffer if
+// it is large enough.  If the input is exhausted before a frame starts, this function returns io.EOF.
+// If the input ends partway through a frame, io.ErrUnexpectedEOF is returned.
+func ReadFrame(input io.Reader, buffer []byte) ([]byte, error) {
+	var header [FrameHeaderSize]byte
+	if _, err := io.ReadFull(input, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+
+	if uint32(cap(buffer)) < size {
+		buffer = make([]byte, size)
+	}
+
+	frame := buffer[:size]
+	if _, err := io.ReadFull(input, frame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+// Transcoder converts a stream of length-prefixed frames, each holding one WRP message, from one format into another.
+// The frame layout is described by FrameHeaderSize.  A Transcoder reuses its frame buffers and its Encoder and Decoder
+// across messages, so transcoding does not allocate per frame once the buffers have grown to the largest frame.
+//
+// A Transcoder is not safe for concurrent use.
+type Transcoder struct {
+	source Format
+	target Format
+
+	decoder Decoder
+	encoder Encoder
+	input   []byte
+	output  []byte
+	message Message
+}
+
+// NewTranscoder creates a Transcoder which reads frames in the source format and writes frames in the target format
+func NewTranscoder(source, target Format) *Transcoder {
+	return &Transcoder{
+		source:  source,
+		target:  target,
+		decoder: NewDecoderBytes(nil, source),
+		encoder: NewEncoderBytes(nil, target),
+	}
+}
+
+// Source returns the Format of the frames this Transcoder reads
+func (t *Transcoder) Source() Format {
+	return t.source
+}
+
+// Target returns the Format of the frames this Transcoder writes
+func (t *Transcoder) Target() Format {
+	return t.target
+}
+
+// Transcode reads frames from input and writes the transcoded frames to output until input is exhausted, returning
+// the number of messages transcoded.  When input ends cleanly between frames, the returned error is nil.  If input ends
+// partway through a frame, io.ErrUnexpectedEOF is returned.  Any other read, decode, encode, or write error stops
+// transcoding and is returned.
+func (t *Transcoder) Transcode(output io.Writer, input io.Reader) (int, error) {
+	count := 0
+	for {
+		frame, err := ReadFrame(input, t.input)
+		if err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return count, err
+		}
+
+		t.input = frame
+		t.message = Message{}
+		t.decoder.ResetBytes(frame)
+		if err := t.decoder.Decode(&t.message); err != nil {
+			return count, err
+		}
+
+		t.output = t.output[:0]
+		t.encoder.ResetBytes(&t.output)
+		if err := t.encoder.Encode(&t.message); err != nil {
+			return count, err
+		}
+
+		if err := WriteFrame(output, t.output); err != nil {
+			return count, err
+		}
+
+		count++
+	}
+}
@@ -0,0 +1,145 @@
+package wrp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTranscoderMessages() []*Message {
+	var status int64 = 200
+	return []*Message{
+		{Type: SimpleEventMessageType, Source: "dns:talaria.comcast.net", Destination: "event:device-status"},
+		{
+			Type:            SimpleRequestResponseMessageType,
+			Source:          "dns:scytale.comcast.net",
+			Destination:     "mac:112233445566/config",
+			TransactionUUID: "c2bb1f16-09c8-11e7-93ae-92361f002671",
+			Status:          &status,
+			Metadata:        map[string]string{"name": "value"},
+			Payload:         []byte{0, 1, 2, 3, 0xfe, 0xff},
+		},
+		{Type: ServiceAliveMessageType},
+	}
+}
+
+// writeTestFrames writes each message as a frame in the given format
+func writeTestFrames(t *testing.T, f Format, messages []*Message) *bytes.Buffer {
+	output := new(bytes.Buffer)
+	for _, message := range messages {
+		var encoded []byte
+		require.NoError(t, NewEncoderBytes(&encoded, f).Encode(message))
+		require.NoError(t, WriteFrame(output, encoded))
+	}
+
+	return output
+}
+
+func testTranscoderTranscode(t *testing.T, source, target Format) {
+	var (
+		assert     = assert.New(t)
+		require    = require.New(t)
+		messages   = testTranscoderMessages()
+		input      = writeTestFrames(t, source, messages)
+		output     = new(bytes.Buffer)
+		transcoder = NewTranscoder(source, target)
+	)
+
+	assert.Equal(source, transcoder.Source())
+	assert.Equal(target, transcoder.Target())
+
+	count, err := transcoder.Transcode(output, input)
+	assert.NoError(err)
+	assert.Equal(len(messages), count)
+
+	var frame []byte
+	for _, expected := range messages {
+		frame, err = ReadFrame(output, frame)
+		require.NoError(err)
+
+		actual := new(Message)
+		require.NoError(NewDecoderBytes(frame, target).Decode(actual))
+		assert.Equal(expected, actual)
+	}
+
+	frame, err = ReadFrame(output, frame)
+	assert.Nil(frame)
+	assert.Equal(io.EOF, err)
+}
+
+func testTranscoderPartialFrame(t *testing.T) {
+	var (
+		assert   = assert.New(t)
+		messages = testTranscoderMessages()
+		input    = writeTestFrames(t, Msgpack, messages)
+		output   = new(bytes.Buffer)
+	)
+
+	// a truncated frame header and a truncated frame body are both partial frames
+	for _, truncated := range [][]byte{input.Bytes()[:input.Len()-1], input.Bytes()[:FrameHeaderSize+1]} {
+		output.Reset()
+		count, err := NewTranscoder(Msgpack, JSON).Transcode(output, bytes.NewReader(truncated))
+		assert.Equal(io.ErrUnexpectedEOF, err)
+		assert.True(count < len(messages))
+	}
+
+	count, err := NewTranscoder(Msgpack, JSON).Transcode(output, bytes.NewReader(input.Bytes()[:2]))
+	assert.Zero(count)
+	assert.Equal(io.ErrUnexpectedEOF, err)
+}
+
+func testTranscoderErrors(t *testing.T) {
+	t.Run("FrameTooLarge", func(t *testing.T) {
+		var (
+			assert = assert.New(t)
+			input  = bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})
+		)
+
+		count, err := NewTranscoder(Msgpack, JSON).Transcode(new(bytes.Buffer), input)
+		assert.Zero(count)
+		assert.Equal(ErrFrameTooLarge, err)
+		assert.Equal(ErrFrameTooLarge, WriteFrame(new(bytes.Buffer), make([]byte, MaxFrameSize+1)))
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		var (
+			assert = assert.New(t)
+			input  = new(bytes.Buffer)
+		)
+
+		WriteFrame(input, []byte("this is not JSON"))
+		count, err := NewTranscoder(JSON, Msgpack).Transcode(new(bytes.Buffer), input)
+		assert.Zero(count)
+		assert.Error(err)
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		var (
+			assert        = assert.New(t)
+			expectedError = errors.New("expected")
+			input         = writeTestFrames(t, Msgpack, testTranscoderMessages())
+		)
+
+		count, err := NewTranscoder(Msgpack, JSON).Transcode(failingWriter{expectedError}, input)
+		assert.Zero(count)
+		assert.Equal(expectedError, err)
+	})
+}
+
+func TestTranscoder(t *testing.T) {
+	for _, source := range AllFormats() {
+		for _, target := range AllFormats() {
+			t.Run(fmt.Sprintf("%sTo%s", source, target), func(t *testing.T) {
+				testTranscoderTranscode(t, source, target)
+			})
+		}
+	}
+
+	t.Run("PartialFrame", testTranscoderPartialFrame)
+	t.Run("Errors", testTranscoderErrors)
+}
@@ -0,0 +1,85 @@
+package wrp
+
+import (
+	"fmt"
+)
+
+const (
+	// spanArity is the number of elements in each span:  the name, start time, and duration.
+	// A span with an error has one more element holding the error text, as produced by SpanFormatter.
+	spanArity = 3
+)
+
+// ValidationError describes a WRP message field that failed validation.  Field is the
+// name of the field as it appears in encoded WRP, e.g. "dest".
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (ve *ValidationError) Error() string {
+	return fmt.Sprintf("Invalid WRP field %s: %s", ve.Field, ve.Reason)
+}
+
+// ValidateLocator checks that a source or destination can be parsed by ParseLocator.  The returned
+// error includes the offending locator.
+func ValidateLocator(locator string) error {
+	if _, _, err := ParseLocator(locator); err != nil {
+		return fmt.Errorf("%s: %q", err, locator)
+	}
+
+	return nil
+}
+
+// requiresLocators tests whether messages of a given type must have both a source and a destination
+func requiresLocators(mt MessageType) bool {
+	switch mt {
+	case SimpleRequestResponseMessageType, SimpleEventMessageType,
+		CreateMessageType, RetrieveMessageType, UpdateMessageType, DeleteMessageType:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks this message for problems that would prevent it from being routed.  The Type must be
+// a known MessageType.  For message types that are routed, i.e. requests, events, and CRUD messages,
+// Source and Destination are required and must be valid locators as defined by ValidateLocator.  For other
+// message types, Source and Destination are only checked if present.  Each of the Spans must have either
+// (3) elements, or (4) elements when the span has an error, as produced by SpanFormatter.
+//
+// Any failure is reported as a *ValidationError.
+func (msg *Message) Validate() error {
+	if !msg.Type.IsKnown() {
+		return &ValidationError{Field: "msg_type", Reason: fmt.Sprintf("Unknown message type %d", msg.Type)}
+	}
+
+	required := requiresLocators(msg.Type)
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"source", msg.Source},
+		{"dest", msg.Destination},
+	} {
+		if len(field.value) == 0 {
+			if required {
+				return &ValidationError{Field: field.name, Reason: fmt.Sprintf("Required for %s messages", msg.Type.FriendlyName())}
+			}
+
+			continue
+		}
+
+		if err := ValidateLocator(field.value); err != nil {
+			return &ValidationError{Field: field.name, Reason: err.Error()}
+		}
+	}
+
+	for i, span := range msg.Spans {
+		if len(span) != spanArity && len(span) != spanArity+1 {
+			return &ValidationError{Field: "spans", Reason: fmt.Sprintf("Span %d has %d elements instead of %d or %d", i, len(span), spanArity, spanArity+1)}
+		}
+	}
+
+	return nil
+}
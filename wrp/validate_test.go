@@ -0,0 +1,101 @@
+package wrp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLocator(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, valid := range []string{
+		"mac:112233445566",
+		"MAC:11:22:33:44:55:66",
+		"mac:11-22-33-44-55-66/config",
+		"uuid:c2bb1f16-09c8-11e7-93ae-92361f002671",
+		"dns:talaria.comcast.net",
+		"serial:1234/service/path",
+		"event:device-status/mac:112233445566/online",
+		"self:/config",
+	} {
+		assert.NoError(ValidateLocator(valid), valid)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"no scheme",
+		"ftp:example.com",
+		"dns:",
+		"dns:/service",
+		"mac:1122334455",
+		"mac:1122334455667788",
+		"mac:11223344556g",
+	} {
+		assert.Error(ValidateLocator(invalid), invalid)
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	testData := []struct {
+		message       Message
+		expectedField string
+	}{
+		{Message{Type: SimpleEventMessageType, Source: "dns:talaria.comcast.net", Destination: "event:device-status"}, ""},
+		{Message{Type: SimpleRequestResponseMessageType, Source: "dns:talaria.comcast.net", Destination: "mac:112233445566/config"}, ""},
+		{Message{Type: RetrieveMessageType, Source: "dns:talaria.comcast.net", Destination: "serial:1234"}, ""},
+		{Message{Type: AuthorizationStatusMessageType}, ""},
+		{Message{Type: ServiceAliveMessageType}, ""},
+		{Message{Type: ServiceRegistrationMessageType, Source: "mac:112233445566"}, ""},
+		{
+			Message{
+				Type:        SimpleEventMessageType,
+				Source:      "dns:talaria.comcast.net",
+				Destination: "event:device-status",
+				Spans:       [][]string{{"name", "1234", "5678"}, {"name", "1234", "5678", "error"}},
+			},
+			"",
+		},
+
+		{Message{}, "msg_type"},
+		{Message{Type: lastMessageType, Source: "dns:talaria.comcast.net", Destination: "mac:112233445566"}, "msg_type"},
+		{Message{Type: SimpleEventMessageType, Destination: "event:device-status"}, "source"},
+		{Message{Type: SimpleEventMessageType, Source: "dns:talaria.comcast.net"}, "dest"},
+		{Message{Type: UpdateMessageType, Source: "talaria", Destination: "mac:112233445566"}, "source"},
+		{Message{Type: DeleteMessageType, Source: "dns:talaria.comcast.net", Destination: "mac:1122"}, "dest"},
+		{Message{Type: ServiceAliveMessageType, Destination: "bad destination"}, "dest"},
+		{
+			Message{
+				Type:        SimpleEventMessageType,
+				Source:      "dns:talaria.comcast.net",
+				Destination: "event:device-status",
+				Spans:       [][]string{{"name", "1234", "5678"}, {"name", "1234"}},
+			},
+			"spans",
+		},
+		{
+			Message{
+				Type:        SimpleEventMessageType,
+				Source:      "dns:talaria.comcast.net",
+				Destination: "event:device-status",
+				Spans:       [][]string{{"name", "1234", "5678", "error", "extra"}},
+			},
+			"spans",
+		},
+	}
+
+	for i, record := range testData {
+		t.Logf("%d: %#v", i, record)
+
+		err := record.message.Validate()
+		if len(record.expectedField) == 0 {
+			assert.NoError(t, err)
+			continue
+		}
+
+		if validationError, ok := err.(*ValidationError); assert.True(t, ok, "expected a *ValidationError, got %T", err) {
+			assert.Equal(t, record.expectedField, validationError.Field)
+			assert.Contains(t, validationError.Error(), record.expectedField)
+		}
+	}
+}
@@ -29,6 +29,25 @@ type Note interface {
 	EncodeBytes(pool *wrp.EncoderPool) ([]byte, error)
 }
 
+// EncodedNote is optionally implemented by a Note which retains the encoded contents it was decoded from.
+// The Notes created by this package, including Requests and Responses, always implement this interface,
+// so a type assertion on any of them succeeds.  It is separate from Note so that Note implementations
+// outside this package need not change.
+type EncodedNote interface {
+	Note
+
+	// Format returns the format of the original encoded contents returned by Bytes.  When Bytes
+	// is empty, as with wrapped messages, the returned Format is meaningless.
+	Format() wrp.Format
+
+	// Bytes returns the original encoded contents this Note was decoded from, or nil if this
+	// Note wraps an existing message.  When the format matches, callers can forward these bytes
+	// as is rather than reencoding the message.
+	//
+	// The returned slice is not a copy.  Callers must never modify it.
+	Bytes() []byte
+}
+
 type note struct {
 	destination   string
 	transactionID string
@@ -49,6 +68,14 @@ func (n *note) Message() *wrp.Message {
 	return n.message
 }
 
+func (n *note) Format() wrp.Format {
+	return n.format
+}
+
+func (n *note) Bytes() []byte {
+	return n.contents
+}
+
 func (n *note) Encode(output io.Writer, pool *wrp.EncoderPool) error {
 	if n.format == pool.Format() && len(n.contents) > 0 {
 		_, err := output.Write(n.contents)
@@ -204,6 +231,43 @@ func (r *response) WithSpans(spans ...tracing.Span) interface{} {
 	return r
 }
 
+// FoldSpans returns a Response whose WRP message carries the spans of the given Response, formatted
+// with the given SpanFormatter and appended to any spans the message already has.  This allows spans
+// to travel inside the encoded message rather than only alongside it, e.g. as HTTP headers.
+//
+// If the given Response has no spans, it is returned as is.  Otherwise, neither the given Response nor
+// its message is modified, and the returned Response is always encoded from its updated message.
+func FoldSpans(r Response, sf wrp.SpanFormatter) Response {
+	spans := r.Spans()
+	if len(spans) == 0 {
+		return r
+	}
+
+	var (
+		message = *r.Message()
+		folded  = sf.Format(spans...)
+	)
+
+	message.Spans = make([][]string, 0, len(r.Message().Spans)+len(folded))
+	message.Spans = append(message.Spans, r.Message().Spans...)
+	message.Spans = append(message.Spans, folded...)
+
+	result := &response{
+		note: note{
+			destination:   r.Destination(),
+			transactionID: r.TransactionID(),
+			message:       &message,
+		},
+		spans: spans,
+	}
+
+	if encoded, ok := r.(EncodedNote); ok {
+		result.format = encoded.Format()
+	}
+
+	return result
+}
+
 // DecodeResponse extracts a WRP response from the given source.
 func DecodeResponse(source io.Reader, pool *wrp.DecoderPool) (Response, error) {
 	contents, err := ioutil.ReadAll(source)
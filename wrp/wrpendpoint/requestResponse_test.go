@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/Comcast/webpa-common/tracing"
 	"github.com/Comcast/webpa-common/wrp"
@@ -125,7 +126,27 @@ func testNoteEncodeBytesUseMessage(t *testing.T) {
 	assert.JSONEq(`{"msg_type": 3, "source": "test", "dest": "test"}`, string(actual))
 }
 
+func testNoteAccessors(t *testing.T) {
+	var (
+		assert   = assert.New(t)
+		contents = []byte("expected contents")
+
+		note = note{
+			contents: contents,
+			format:   wrp.JSON,
+		}
+	)
+
+	assert.Equal(wrp.JSON, note.Format())
+	assert.Equal(contents, note.Bytes())
+
+	// the contents must not be copied
+	assert.True(&contents[0] == &note.Bytes()[0])
+}
+
 func TestNote(t *testing.T) {
+	t.Run("Accessors", testNoteAccessors)
+
 	t.Run("Encode", func(t *testing.T) {
 		t.Run("UseContents", testNoteEncodeUseContents)
 		t.Run("UseMessage", testNoteEncodeUseMessage)
@@ -177,6 +198,9 @@ func testDecodeRequestBytes(t *testing.T, logger log.Logger, source []byte, form
 
 	assertLogger(t, request, logger)
 	assertNote(t, original, request)
+	require.Implements((*EncodedNote)(nil), request)
+	require.Equal(format, request.(EncodedNote).Format())
+	require.Equal(source, request.(EncodedNote).Bytes())
 }
 
 func testDecodeRequestBytesDecodeError(t *testing.T, format wrp.Format) {
@@ -346,6 +370,60 @@ func testResponseSpans(t *testing.T, message wrp.Message) {
 	assert.Equal(errors.New("expected"), replaceSpans.Spans()[1].Error())
 }
 
+func testResponseFoldSpans(t *testing.T, message wrp.Message, format wrp.Format, encoded []byte) {
+	var (
+		require = require.New(t)
+		assert  = assert.New(t)
+
+		start   = time.Date(2017, time.June, 14, 10, 30, 0, 0, time.UTC)
+		spanner = tracing.NewSpanner(
+			tracing.Now(func() time.Time { return start }),
+			tracing.Since(func(time.Time) time.Duration { return 0 }),
+		)
+
+		existing  = []string{"existing", "2017-06-14T10:00:00Z", "1s"}
+		formatter wrp.SpanFormatter
+	)
+
+	original, err := DecodeResponseBytes(encoded, wrp.NewDecoderPool(1, format))
+	require.NoError(err)
+	assert.True(original == FoldSpans(original, formatter))
+
+	withSpans := original.WithSpans(spanner.Start("first")(nil), spanner.Start("second")(errors.New("expected"))).(Response)
+	folded := FoldSpans(withSpans, formatter)
+	require.NotNil(folded)
+	assert.Equal(withSpans.Spans(), folded.Spans())
+	require.Implements((*EncodedNote)(nil), folded)
+	assert.Empty(folded.(EncodedNote).Bytes())
+	assert.Equal(format, folded.(EncodedNote).Format())
+	assert.Equal(original.Destination(), folded.Destination())
+	assert.Equal(original.TransactionID(), folded.TransactionID())
+
+	// the original message is untouched
+	assert.Empty(original.Message().Spans)
+
+	expectedSpans := [][]string{
+		{"first", "2017-06-14T10:30:00Z", "0s"},
+		{"second", "2017-06-14T10:30:00Z", "0s", "expected"},
+	}
+
+	assert.Equal(expectedSpans, folded.Message().Spans)
+
+	// the folded spans are part of the encoded message
+	reencoded, err := folded.EncodeBytes(wrp.NewEncoderPool(1, format))
+	require.NoError(err)
+
+	var decoded wrp.Message
+	require.NoError(wrp.NewDecoderBytes(reencoded, format).Decode(&decoded))
+	assert.Equal(expectedSpans, decoded.Spans)
+
+	// existing spans are preserved
+	message.Spans = [][]string{existing}
+	folded = FoldSpans(WrapAsResponse(&message).WithSpans(spanner.Start("first")(nil)).(Response), formatter)
+	assert.Equal([][]string{existing, expectedSpans[0]}, folded.Message().Spans)
+	assert.Equal([][]string{existing}, message.Spans)
+}
+
 func TestResponse(t *testing.T) {
 	var (
 		require     = require.New(t)
@@ -393,6 +471,10 @@ func TestResponse(t *testing.T) {
 			t.Run("Spans", func(t *testing.T) {
 				testResponseSpans(t, testMessage)
 			})
+
+			t.Run("FoldSpans", func(t *testing.T) {
+				testResponseFoldSpans(t, testMessage, format, encoded)
+			})
 		})
 	}
 }
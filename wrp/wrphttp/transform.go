@@ -0,0 +1,19 @@
+package wrphttp
+
+// TransformPayload applies a transform to the payload of an Entity's decoded message.  The transform
+// receives the message's content type, so that a single transform can handle several payload types.
+// This is the extension point for payload-level middleware, e.g. redacting fields in a proxy.
+//
+// When the transform succeeds, its result replaces the message payload and the entity's Contents are
+// cleared, so that EncodeRequest re-encodes the message rather than sending the stale original bytes.
+// If the transform returns an error, the entity is left unchanged and that error is returned.
+func TransformPayload(e *Entity, fn func(contentType string, payload []byte) ([]byte, error)) error {
+	payload, err := fn(e.Message.ContentType, e.Message.Payload)
+	if err != nil {
+		return err
+	}
+
+	e.Message.Payload = payload
+	e.Contents = nil
+	return nil
+}
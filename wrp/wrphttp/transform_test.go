@@ -0,0 +1,103 @@
+package wrphttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/Comcast/webpa-common/wrp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTransformTestEntity(t *testing.T, f wrp.Format) *Entity {
+	entity := &Entity{
+		Format: f,
+		Message: wrp.Message{
+			Type:        wrp.SimpleEventMessageType,
+			Source:      "dns:proxy.comcast.net",
+			Destination: "event:device-status",
+			ContentType: "application/json",
+			Payload:     []byte(`{"name": "value", "ssn": "123-45-6789"}`),
+		},
+	}
+
+	require.NoError(t, wrp.NewEncoderBytes(&entity.Contents, f).Encode(&entity.Message))
+	return entity
+}
+
+func redactSSN(contentType string, payload []byte) ([]byte, error) {
+	if contentType != "application/json" {
+		return payload, nil
+	}
+
+	var fields map[string]string
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+
+	delete(fields, "ssn")
+	return json.Marshal(fields)
+}
+
+func testTransformPayloadSuccess(t *testing.T, entityFormat, encodeFormat wrp.Format) {
+	var (
+		assert      = assert.New(t)
+		require     = require.New(t)
+		entity      = newTransformTestEntity(t, entityFormat)
+		httpRequest = &http.Request{Header: http.Header{}}
+	)
+
+	require.NoError(TransformPayload(entity, redactSSN))
+	assert.JSONEq(`{"name": "value"}`, string(entity.Message.Payload))
+	assert.Empty(entity.Contents)
+
+	require.NoError(EncodeRequest(encodeFormat)(context.Background(), httpRequest, entity))
+	body, err := ioutil.ReadAll(httpRequest.Body)
+	require.NoError(err)
+
+	var encoded wrp.Message
+	require.NoError(wrp.NewDecoderBytes(body, encodeFormat).Decode(&encoded))
+	assert.Equal(entity.Message, encoded)
+	assert.JSONEq(`{"name": "value"}`, string(encoded.Payload))
+}
+
+func testTransformPayloadError(t *testing.T) {
+	var (
+		assert           = assert.New(t)
+		entity           = newTransformTestEntity(t, wrp.Msgpack)
+		expectedContents = append([]byte(nil), entity.Contents...)
+		expectedPayload  = append([]byte(nil), entity.Message.Payload...)
+		expectedError    = errors.New("expected")
+	)
+
+	assert.Equal(
+		expectedError,
+		TransformPayload(entity, func(contentType string, payload []byte) ([]byte, error) {
+			assert.Equal("application/json", contentType)
+			assert.True(bytes.Equal(expectedPayload, payload))
+			return nil, expectedError
+		}),
+	)
+
+	assert.Equal(expectedContents, entity.Contents)
+	assert.Equal(expectedPayload, entity.Message.Payload)
+}
+
+func TestTransformPayload(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		for _, entityFormat := range wrp.AllFormats() {
+			for _, encodeFormat := range wrp.AllFormats() {
+				t.Run(entityFormat.String()+"To"+encodeFormat.String(), func(t *testing.T) {
+					testTransformPayloadSuccess(t, entityFormat, encodeFormat)
+				})
+			}
+		}
+	})
+
+	t.Run("Error", testTransformPayloadError)
+}